@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CustomCommand is a command from the config file that can be run in the
+// highlighted or selected projects, such as `terraform fmt -recursive`.
+type CustomCommand struct {
+	Name string `json:"name"`
+
+	// Command is the program and its arguments. Each of them is a template
+	// that can use the project's {{.Path}}, {{.Name}} and {{.Workspace}}.
+	Command []string `json:"command"`
+
+	// Key runs the command in the highlighted project and SelectedKey in the
+	// selected projects.
+	Key         []string `json:"key"`
+	SelectedKey []string `json:"selectedKey"`
+
+	Confirm bool `json:"confirm"`
+
+	// The command succeeds if it exits with one of SuccessCodes (0 by
+	// default), its output matches SuccessPattern if set, and its output
+	// doesn't match FailurePattern if set.
+	SuccessCodes   []int  `json:"successCodes"`
+	SuccessPattern string `json:"successPattern"`
+	FailurePattern string `json:"failurePattern"`
+
+	successRegexp *regexp.Regexp
+	failureRegexp *regexp.Regexp
+}
+
+type UpdateCommandMsg Project
+
+// validateCommands checks the commands from the config file and compiles
+// their patterns.
+func validateCommands(commands []CustomCommand) error {
+	names := []string{}
+	for i := range commands {
+		command := &commands[i]
+		if command.Name == "" {
+			return fmt.Errorf("every command needs a name")
+		}
+		if slices.Contains(names, command.Name) {
+			return fmt.Errorf("command %q is defined twice", command.Name)
+		}
+		names = append(names, command.Name)
+
+		if len(command.Command) == 0 {
+			return fmt.Errorf("command %q has no command to run", command.Name)
+		}
+		if len(command.Key) == 0 && len(command.SelectedKey) == 0 {
+			return fmt.Errorf("command %q needs a key or selectedKey", command.Name)
+		}
+		if _, err := command.templates(); err != nil {
+			return fmt.Errorf("command %q: %w", command.Name, err)
+		}
+		if err := command.compilePatterns(); err != nil {
+			return fmt.Errorf("command %q: %w", command.Name, err)
+		}
+	}
+	return nil
+}
+
+// compilePatterns compiles the success and failure patterns once, so that
+// succeeded doesn't have to on every run.
+func (c *CustomCommand) compilePatterns() (err error) {
+	c.successRegexp, c.failureRegexp = nil, nil
+	if c.SuccessPattern != "" {
+		if c.successRegexp, err = regexp.Compile(c.SuccessPattern); err != nil {
+			return err
+		}
+	}
+	if c.FailurePattern != "" {
+		if c.failureRegexp, err = regexp.Compile(c.FailurePattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c CustomCommand) templates() ([]*template.Template, error) {
+	templates := []*template.Template{}
+	for _, arg := range c.Command {
+		t, err := template.New(c.Name).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// expand fills in the project's fields in the command's arguments.
+func (c CustomCommand) expand(project Project) ([]string, error) {
+	templates, err := c.templates()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{}
+	for _, t := range templates {
+		arg := strings.Builder{}
+		if err := t.Execute(&arg, project); err != nil {
+			return nil, err
+		}
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// succeeded interprets the exit code and output of a run of the command.
+func (c CustomCommand) succeeded(exitCode int, output string) bool {
+	codes := c.SuccessCodes
+	if len(codes) == 0 {
+		codes = []int{0}
+	}
+	if !slices.Contains(codes, exitCode) {
+		return false
+	}
+	if c.successRegexp != nil && !c.successRegexp.MatchString(output) {
+		return false
+	}
+	if c.failureRegexp != nil && c.failureRegexp.MatchString(output) {
+		return false
+	}
+	return true
+}
+
+// commandBindings returns the key bindings of the custom commands for the
+// help and conflict checks.
+func commandBindings(commands []CustomCommand) []key.Binding {
+	bindings := []key.Binding{}
+	for _, command := range commands {
+		if len(command.Key) > 0 {
+			bindings = append(bindings, key.NewBinding(
+				key.WithKeys(command.Key...),
+				key.WithHelp(helpKey(command.Key), command.Name),
+			))
+		}
+		if len(command.SelectedKey) > 0 {
+			bindings = append(bindings, key.NewBinding(
+				key.WithKeys(command.SelectedKey...),
+				key.WithHelp(helpKey(command.SelectedKey), command.Name+": selected"),
+			))
+		}
+	}
+	return bindings
+}
+
+func runCustomCommand(project *Project, command CustomCommand) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		output, err := executeCustomCommand(*project, command)
+		recordRun(project, start)
+
+		project.LastAction = TerraformCommand(command.Name)
+		project.Output = output
+		project.Command = command.Name
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			project.CommandStatus = ConfigTimeout
+		case err != nil && !isExitError(err):
+			project.CommandStatus = ConfigInvalid
+		case command.succeeded(exitCode(err), removeANSIEscapeCodes(output)):
+			project.CommandStatus = ConfigValid
+		default:
+			project.CommandStatus = ConfigInvalid
+		}
+		return UpdateCommandMsg(*project)
+	}
+}
+
+// executeCustomCommand runs a custom command in the project directory with
+// the project's environment and profile. The returned error is set if the
+// command failed to start, exited with an error or timed out.
+func executeCustomCommand(project Project, command CustomCommand) (string, error) {
+	args, err := command.expand(project)
+	if err != nil {
+		return fmt.Sprintf("%s: %s", command.Name, err), err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if timeout := Settings.timeout(command.Name); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	out := bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, projectProgram(project, args[0]), args[1:]...)
+	cmd.Dir = project.Path
+	cmd.Env = append(os.Environ(), projectEnv(project)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	output := fmt.Sprintf("$ %s\n%s", strings.Join(args, " "), out.String())
+	if err != nil {
+		output += fmt.Sprintf("\n%s: %s\n", command.Name, err)
+	}
+	return maskedOutput(project.Path, output), err
+}
+
+// formatCommand shows the last custom command run in a project and whether
+// it succeeded.
+func formatCommand(project Project) string {
+	if project.Command == "" {
+		return tableDate.Render("-")
+	}
+	return project.Command + " " + formatValid(project.CommandStatus)
+}
+
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+// startCustomCommand runs the custom command bound to a key in the
+// highlighted or selected projects, asking first if the command needs
+// confirmation. It returns nil if no command is bound to the key.
+func (m *MainModel) startCustomCommand(msg tea.KeyMsg) tea.Cmd {
+	for _, command := range Settings.Commands {
+		targets := []*Project{}
+		description := ""
+		switch {
+		case slices.Contains(command.Key, msg.String()):
+			project := m.highlightedRow()
+			if highlighted := matchProjectInMemory(project.Path, &m.projects); highlighted != nil {
+				targets = append(targets, highlighted)
+			}
+			description = project.Name
+
+		case slices.Contains(command.SelectedKey, msg.String()):
+			for _, path := range m.table.visibleSelectedPaths() {
+				if project := matchProjectInMemory(path, &m.projects); project != nil {
+					targets = append(targets, project)
+				}
+			}
+			description = "selected projects"
+
+		default:
+			continue
+		}
+
+		if len(targets) == 0 {
+			return nil
+		}
+		task := func(m *MainModel) tea.Cmd {
+			m.message = fmt.Sprintf("%s: %s", command.Name, description)
+			batchArgs := []tea.Cmd{}
+			for _, project := range targets {
+				batchArgs = append(batchArgs, runCustomCommand(project, command))
+			}
+			return tea.Sequence(tea.Batch(batchArgs...), updatesFinished)
+		}
+
+		if command.Confirm {
+			m.confirm(fmt.Sprintf("This will run %s in %d projects", command.Name, len(targets)), task)
+			return nil
+		}
+		m.working = true
+		return tea.Batch(m.spinner.Tick, task(m))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandCommand(t *testing.T) {
+	command := CustomCommand{Name: "docs", Command: []string{"terraform-docs", "markdown", "{{.Path}}", "--header={{.Name}}"}}
+	args, err := command.expand(Project{Name: "api", Path: "/infra/api"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"terraform-docs", "markdown", "/infra/api", "--header=api"}
+	if !slices.Equal(args, want) {
+		t.Errorf("Expected %v, got %v", want, args)
+	}
+
+	command.Command = []string{"echo", "{{.Nope}}"}
+	if _, err := command.expand(Project{}); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestCommandSucceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  CustomCommand
+		exitCode int
+		output   string
+		want     bool
+	}{
+		{"Default code", CustomCommand{}, 0, "", true},
+		{"Failing code", CustomCommand{}, 1, "", false},
+		{"Custom codes", CustomCommand{SuccessCodes: []int{0, 2}}, 2, "", true},
+		{"Success pattern", CustomCommand{SuccessPattern: "no issues"}, 0, "3 issues", false},
+		{"Failure pattern", CustomCommand{FailurePattern: "(?i)warning"}, 0, "WARNING: deprecated", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.command.compilePatterns(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := test.command.succeeded(test.exitCode, test.output); got != test.want {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	valid := CustomCommand{Name: "lint", Command: []string{"tflint"}, Key: []string{"T"}}
+	if err := validateCommands([]CustomCommand{valid}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	invalid := map[string]CustomCommand{
+		"no name":      {Command: []string{"tflint"}, Key: []string{"T"}},
+		"no command":   {Name: "lint", Key: []string{"T"}},
+		"no key":       {Name: "lint", Command: []string{"tflint"}},
+		"bad pattern":  {Name: "lint", Command: []string{"tflint"}, Key: []string{"T"}, SuccessPattern: "("},
+		"bad template": {Name: "lint", Command: []string{"tflint", "{{.Path"}, Key: []string{"T"}},
+	}
+	for _, name := range sortedKeys(invalid) {
+		if err := validateCommands([]CustomCommand{invalid[name]}); err == nil {
+			t.Errorf("Expected an error for a command with %s", name)
+		}
+	}
+	if err := validateCommands([]CustomCommand{valid, valid}); err == nil {
+		t.Error("Expected an error for a duplicate command")
+	}
+}
+
+func TestRunCustomCommand(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		project := Project{Name: "api", Path: t.TempDir()}
+		command := CustomCommand{Name: "greet", Command: []string{"sh", "-c", "echo hello {{.Name}}"}}
+		runCustomCommand(&project, command)()
+
+		if project.Command != "greet" || project.CommandStatus != ConfigValid {
+			t.Errorf("Expected a successful greet, got %q and %q", project.Command, project.CommandStatus)
+		}
+		if !strings.Contains(project.Output, "hello api") {
+			t.Errorf("Expected the command output, got %q", project.Output)
+		}
+	})
+
+	t.Run("Uses the project's Terraform", func(t *testing.T) {
+		project := Project{Path: fakeTerraform(t, "echo project terraform $@")}
+		runCustomCommand(&project, CustomCommand{Name: "fmt", Command: []string{"terraform", "fmt", "-recursive"}})()
+		if !strings.Contains(project.Output, "project terraform fmt -recursive") {
+			t.Errorf("Expected the project's Terraform binary to run, got %q", project.Output)
+		}
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		project := Project{Path: t.TempDir()}
+		runCustomCommand(&project, CustomCommand{Name: "fail", Command: []string{"sh", "-c", "exit 3"}})()
+		if project.CommandStatus != ConfigInvalid {
+			t.Errorf("Expected a failed command, got %q", project.CommandStatus)
+		}
+
+		runCustomCommand(&project, CustomCommand{Name: "fail", Command: []string{"sh", "-c", "exit 3"}, SuccessCodes: []int{3}})()
+		if project.CommandStatus != ConfigValid {
+			t.Errorf("Expected exit code 3 to succeed, got %q", project.CommandStatus)
+		}
+	})
+}
+
+func TestCommandKeyConflicts(t *testing.T) {
+	keys := mainKeys
+	keys.Commands = commandBindings([]CustomCommand{{Name: "lint", Command: []string{"tflint"}, Key: []string{"p"}}})
+
+	conflicts := keys.conflicts()
+	if len(conflicts) == 0 {
+		t.Fatal("Expected a conflict for the lint command")
+	}
+	for _, conflict := range conflicts {
+		if !strings.Contains(conflict, "lint") {
+			t.Errorf("Expected only conflicts for lint, got %v", conflicts)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const ConfigFileName = "config.json"
+
+// Settings holds the configuration loaded from the config file, or the
+// defaults if there is no config file.
+var Settings = defaultConfig()
+
+type Config struct {
+	Timeouts       map[string]Duration        `json:"timeouts"`
+	DefaultTimeout Duration                   `json:"defaultTimeout"`
+	IdleTimeout    Duration                   `json:"idleTimeout"`
+	Keys           map[string][]string        `json:"keys"`
+	Theme          string                     `json:"theme"`
+	Themes         map[string]json.RawMessage `json:"themes"`
+	Columns        []string                   `json:"columns"`
+	Split          SplitMode                  `json:"split"`
+	SplitSize      int                        `json:"splitSize"`
+	Mouse          bool                       `json:"mouse"`
+	Groups         Groups                     `json:"groups"`
+
+	// TerraformVersions is a directory of installed Terraform versions, such
+	// as ~/.tfenv/versions or ~/.asdf/installs/terraform.
+	TerraformVersions string `json:"terraformVersions"`
+
+	// LockPlatforms are the platforms that `terraform providers lock` adds
+	// checksums for.
+	LockPlatforms []string `json:"lockPlatforms"`
+
+	// ProjectProfiles assigns profiles to projects by path or glob pattern,
+	// relative to the search path.
+	Profiles        map[string]Profile `json:"profiles"`
+	ProjectProfiles map[string]string  `json:"projectProfiles"`
+
+	// Hooks are the commands run around validate, plan and apply, keyed by
+	// stage such as pre-plan.
+	Hooks map[string][]Hook `json:"hooks"`
+
+	Commands []CustomCommand `json:"commands"`
+}
+
+// Duration is a time.Duration that is written as a string such as "10m" in
+// the config file.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10m\": %s", data)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Timeouts: map[string]Duration{
+			Validate.String(): {5 * time.Minute},
+			Plan.String():     {30 * time.Minute},
+			Apply.String():    {0},
+		},
+		DefaultTimeout: Duration{10 * time.Minute},
+		IdleTimeout:    Duration{10 * time.Minute},
+		Theme:          AutoTheme,
+		Columns:        defaultColumns,
+		Split:          SplitNone,
+		SplitSize:      defaultSplitSize,
+		LockPlatforms:  []string{"linux_amd64", "darwin_amd64", "darwin_arm64", "windows_amd64"},
+	}
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tarragon", ConfigFileName)
+}
+
+// loadConfig reads the config file at path on top of the defaults. A missing
+// file is not an error.
+func loadConfig(path string) (Config, error) {
+	config := defaultConfig()
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	if slices.Equal(config.Columns, defaultColumns) {
+		config.Columns = config.defaultColumns()
+	}
+	if err := validateColumns(config.Columns); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateSplit(config.Split, config.SplitSize); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateProfiles(config.Profiles, config.ProjectProfiles); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateHooks(config.Hooks); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateCommands(config.Commands); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// timeout returns how long a Terraform subcommand may run before it is
+// stopped. Zero means no limit.
+func (c Config) timeout(subcommand string) time.Duration {
+	if timeout, ok := c.Timeouts[subcommand]; ok {
+		return timeout.Duration
+	}
+	return c.DefaultTimeout.Duration
+}
+
+// idleTimeout returns how long a Terraform subcommand may run without output
+// before it is stopped. Zero means no limit, as for subcommands that write
+// state.
+func (c Config) idleTimeout(subcommand string) time.Duration {
+	if slices.Contains(stateSubcommands, subcommand) {
+		return 0
+	}
+	return c.IdleTimeout.Duration
+}
+
+// defaultColumns returns the columns shown unless the config chooses them,
+// adding the profile, hooks and command columns if those are configured.
+func (c Config) defaultColumns() []string {
+	columns := slices.Clone(defaultColumns)
+	if len(c.Hooks) > 0 {
+		columns = slices.Insert(columns, slices.Index(columns, "valid")+1, "hooks")
+	}
+	if len(c.Profiles) > 0 {
+		columns = slices.Insert(columns, 1, "profile")
+	}
+	if len(c.Commands) > 0 {
+		columns = append(columns, "command")
+	}
+	return columns
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Missing file uses defaults", func(t *testing.T) {
+		config, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := config.timeout("plan"); got != 30*time.Minute {
+			t.Errorf("Expected default plan timeout, got %s", got)
+		}
+		if got := config.timeout("apply"); got != 0 {
+			t.Errorf("Expected no default apply timeout, got %s", got)
+		}
+		if got := config.idleTimeout("apply"); got != 0 {
+			t.Errorf("Expected no idle timeout for apply, got %s", got)
+		}
+		if got := config.idleTimeout("plan"); got != 10*time.Minute {
+			t.Errorf("Expected default plan idle timeout, got %s", got)
+		}
+	})
+
+	t.Run("Overrides defaults", func(t *testing.T) {
+		path := writeConfig(t, `{"timeouts": {"plan": "1m"}, "idleTimeout": "0s"}`)
+		config, err := loadConfig(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := config.timeout("plan"); got != time.Minute {
+			t.Errorf("Expected plan timeout of 1m, got %s", got)
+		}
+		if got := config.timeout("validate"); got != 5*time.Minute {
+			t.Errorf("Expected default validate timeout to be kept, got %s", got)
+		}
+		if got := config.timeout("state"); got != config.DefaultTimeout.Duration {
+			t.Errorf("Expected default timeout for other commands, got %s", got)
+		}
+		if config.IdleTimeout.Duration != 0 {
+			t.Errorf("Expected idle timeout to be disabled, got %s", config.IdleTimeout)
+		}
+	})
+
+	t.Run("Invalid split", func(t *testing.T) {
+		for _, content := range []string{`{"split": "diagonal"}`, `{"split": "vertical", "splitSize": 95}`} {
+			if _, err := loadConfig(writeConfig(t, content)); err == nil {
+				t.Errorf("Expected an error for %s", content)
+			}
+		}
+	})
+
+	t.Run("Profiles", func(t *testing.T) {
+		config, err := loadConfig(writeConfig(t, `{"profiles": {"prod": {"env": {"AWS_PROFILE": "prod"}}}, "projectProfiles": {"prod/*": "prod"}}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Contains(config.Columns, "profile") {
+			t.Errorf("Expected the profile column to be shown, got %v", config.Columns)
+		}
+
+		if _, err := loadConfig(writeConfig(t, `{"projectProfiles": {"prod/*": "missing"}}`)); err == nil {
+			t.Error("Expected an error for an unknown profile")
+		}
+	})
+
+	t.Run("Invalid duration", func(t *testing.T) {
+		path := writeConfig(t, `{"idleTimeout": 10}`)
+		if _, err := loadConfig(path); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -6,13 +6,17 @@ import (
 	"github.com/erikgeiser/promptkit/confirmation"
 )
 
-func createConfirmation() *confirmation.Model {
-	text := []string{"Are you sure?", warning.Render("This will apply with auto-approve"), "..."}
+const applyWarning = "This will apply with auto-approve"
+
+func createConfirmation(message string) *confirmation.Model {
+	text := []string{"Are you sure?", warning.Render(message), "..."}
 	prompt := confirmation.New(strings.Join(text, " "), confirmation.Undecided)
 	prompt.Template = confirmation.TemplateYN
 	prompt.ResultTemplate = confirmation.ResultTemplateYN
-	prompt.KeyMap.SelectYes = append(prompt.KeyMap.SelectYes, "y")
-	prompt.KeyMap.SelectNo = append(prompt.KeyMap.SelectNo, "n")
+	prompt.KeyMap.Yes = mainKeys.Yes.Keys()
+	prompt.KeyMap.No = mainKeys.No.Keys()
+	prompt.KeyMap.SelectYes = mainKeys.Yes.Keys()
+	prompt.KeyMap.SelectNo = mainKeys.No.Keys()
 	model := confirmation.NewModel(prompt)
 	model.Init()
 	return model
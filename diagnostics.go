@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidateResult is the output of `terraform validate -json`.
+type ValidateResult struct {
+	Valid        bool         `json:"valid"`
+	ErrorCount   int          `json:"error_count"`
+	WarningCount int          `json:"warning_count"`
+	Diagnostics  []Diagnostic `json:"diagnostics"`
+}
+
+type Diagnostic struct {
+	Severity string       `json:"severity"`
+	Summary  string       `json:"summary"`
+	Detail   string       `json:"detail"`
+	Range    *SourceRange `json:"range"`
+}
+
+// SourceRange is the part of a file a diagnostic refers to. The filename is
+// relative to the project directory.
+type SourceRange struct {
+	Filename string         `json:"filename"`
+	Start    SourcePosition `json:"start"`
+	End      SourcePosition `json:"end"`
+}
+
+type SourcePosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// executeValidate runs `terraform validate -json` and renders the diagnostics
+// the way Terraform prints them without -json. If Terraform did not print a
+// result, e.g. because the project is not initialized, the error is set and
+// the output holds whatever it printed instead.
+func executeValidate(dir string) (ValidateResult, string, error) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	runErr := runTerraform(dir, &stdout, &stderr, Validate.String(), "-json")
+	output := maskedOutput(dir, stdout.String()+stderr.String())
+	if err := stopError(runErr); err != nil {
+		return ValidateResult{}, output, err
+	}
+
+	var result ValidateResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		if runErr != nil {
+			return result, output, runErr
+		}
+		return result, output, fmt.Errorf("could not parse validate output: %w", err)
+	}
+	return result, formatDiagnostics(result), nil
+}
+
+func (d Diagnostic) location() string {
+	if d.Range == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s line %d", d.Range.Filename, d.Range.Start.Line)
+}
+
+func formatDiagnostic(d Diagnostic) string {
+	label := "Error"
+	if d.Severity == SeverityWarning {
+		label = "Warning"
+	}
+	text := fmt.Sprintf("%s: %s", label, d.Summary)
+	if location := d.location(); location != "" {
+		text += fmt.Sprintf("\n\n  on %s:", location)
+	}
+	if d.Detail != "" {
+		text += "\n\n" + d.Detail
+	}
+	return text
+}
+
+// formatDiagnostics renders a validate result the way Terraform prints it
+// without -json.
+func formatDiagnostics(result ValidateResult) string {
+	parts := []string{}
+	for _, diagnostic := range result.Diagnostics {
+		parts = append(parts, formatDiagnostic(diagnostic))
+	}
+
+	switch {
+	case result.Valid && len(parts) > 0:
+		parts = append(parts, "Success! The configuration is valid, but there were some validation warnings as shown above.")
+	case result.Valid:
+		parts = append(parts, "Success! The configuration is valid.")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func diagnosticsWithSeverity(diagnostics []Diagnostic, severity string) []Diagnostic {
+	matching := []Diagnostic{}
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == severity {
+			matching = append(matching, diagnostic)
+		}
+	}
+	return matching
+}
+
+// relativeProjectPath returns a project path relative to the search path, as
+// used to identify projects in CI reports.
+func relativeProjectPath(path string) string {
+	rel, err := filepath.Rel(SearchPath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	Classname string       `xml:"classname,attr"`
+	Failure   *junitResult `xml:"failure,omitempty"`
+	Error     *junitResult `xml:"error,omitempty"`
+	Skipped   *junitResult `xml:"skipped,omitempty"`
+	SystemOut string       `xml:"system-out,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnitReport writes one testcase per project. Invalid projects fail,
+// timed out projects are errors and projects that were never validated are
+// skipped; warnings are kept in the system output.
+func renderJUnitReport(projects []Project, generated time.Time) (string, error) {
+	suite := junitTestSuite{Name: "terraform validate", Timestamp: generated.Format(time.RFC3339)}
+
+	for _, project := range projects {
+		testCase := junitTestCase{Name: relativeProjectPath(project.Path), Classname: "terraform.validate"}
+		warnings := []string{}
+		for _, diagnostic := range diagnosticsWithSeverity(project.Diagnostics, SeverityWarning) {
+			warnings = append(warnings, formatDiagnostic(diagnostic))
+		}
+		testCase.SystemOut = strings.Join(warnings, "\n\n")
+
+		switch project.Valid {
+		case ConfigInvalid:
+			errors := diagnosticsWithSeverity(project.Diagnostics, SeverityError)
+			text := []string{}
+			for _, diagnostic := range errors {
+				text = append(text, formatDiagnostic(diagnostic))
+			}
+			if len(text) == 0 {
+				text = append(text, strings.TrimSpace(removeANSIEscapeCodes(project.Output)))
+			}
+			testCase.Failure = &junitResult{
+				Message: fmt.Sprintf("%d error(s)", len(errors)),
+				Type:    SeverityError,
+				Text:    strings.Join(text, "\n\n"),
+			}
+			suite.Failures++
+		case ConfigTimeout:
+			testCase.Error = &junitResult{Message: "timed out", Text: strings.TrimSpace(removeANSIEscapeCodes(project.Output))}
+			suite.Errors++
+		case ConfigValid:
+		default:
+			testCase.Skipped = &junitResult{Message: "not validated"}
+			suite.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	suites := junitTestSuites{
+		Name:     "tarragon",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}
+	output, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(output) + "\n", nil
+}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifRuleID  = "terraform-validate"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// renderSARIFReport writes one result per diagnostic, located relative to the
+// search path so that code scanning tools can link to the file.
+func renderSARIFReport(projects []Project) (string, error) {
+	results := []sarifResult{}
+	for _, project := range projects {
+		for _, diagnostic := range project.Diagnostics {
+			message := diagnostic.Summary
+			if diagnostic.Detail != "" {
+				message += "\n\n" + diagnostic.Detail
+			}
+
+			result := sarifResult{RuleID: sarifRuleID, Level: diagnostic.Severity, Message: sarifMessage{message}}
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: relativeProjectPath(project.Path)},
+			}}
+			if r := diagnostic.Range; r != nil {
+				location.PhysicalLocation.ArtifactLocation.URI = relativeProjectPath(filepath.Join(project.Path, r.Filename))
+				location.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   r.Start.Line,
+					StartColumn: r.Start.Column,
+					EndLine:     r.End.Line,
+					EndColumn:   r.End.Column,
+				}
+			}
+			result.Locations = []sarifLocation{location}
+			results = append(results, result)
+		}
+	}
+
+	sarif := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "tarragon",
+				InformationURI: "https://github.com/sho-87/tarragon",
+				Version:        version,
+				Rules: []sarifRule{{
+					ID:               sarifRuleID,
+					ShortDescription: sarifMessage{"terraform validate diagnostic"},
+				}},
+			}},
+			Results: results,
+		}},
+	}
+	output, err := json.MarshalIndent(sarif, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validateOutput = `{
+  "format_version": "1.0",
+  "valid": false,
+  "error_count": 1,
+  "warning_count": 1,
+  "diagnostics": [
+    {
+      "severity": "error",
+      "summary": "Unsupported argument",
+      "detail": "An argument named \"foo\" is not expected here.",
+      "range": {
+        "filename": "main.tf",
+        "start": {"line": 3, "column": 3, "byte": 40},
+        "end": {"line": 3, "column": 6, "byte": 43}
+      }
+    },
+    {
+      "severity": "warning",
+      "summary": "Deprecated attribute",
+      "detail": ""
+    }
+  ]
+}`
+
+func parseTestDiagnostics(t *testing.T) ValidateResult {
+	var result ValidateResult
+	if err := json.Unmarshal([]byte(validateOutput), &result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestFormatDiagnostics(t *testing.T) {
+	t.Run("Invalid", func(t *testing.T) {
+		got := formatDiagnostics(parseTestDiagnostics(t))
+		want := "Error: Unsupported argument\n\n  on main.tf line 3:\n\nAn argument named \"foo\" is not expected here.\n\nWarning: Deprecated attribute"
+		if got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		got := formatDiagnostics(ValidateResult{Valid: true})
+		if got != "Success! The configuration is valid." {
+			t.Errorf("Unexpected output: %q", got)
+		}
+	})
+}
+
+func TestRunValidate(t *testing.T) {
+	script := `echo validate >> "$(dirname "$0")/runs"
+echo '{"valid":false,"error_count":1,"warning_count":0,"diagnostics":[{"severity":"error","summary":"Unsupported argument"}]}'
+exit 1`
+	project := Project{Path: fakeTerraform(t, script)}
+	runValidate(&project)()
+
+	if project.Valid != ConfigInvalid || len(project.Diagnostics) != 1 {
+		t.Errorf("Expected an invalid project with 1 diagnostic, got %q and %v", project.Valid, project.Diagnostics)
+	}
+	if project.Output != "Error: Unsupported argument" {
+		t.Errorf("Expected the rendered diagnostics, got %q", project.Output)
+	}
+	binary, _ := terraformBinaries.get(project.Path)
+	runs, err := os.ReadFile(filepath.Join(filepath.Dir(binary), "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(runs), "validate"); got != 1 {
+		t.Errorf("Expected Terraform to run once, got %d", got)
+	}
+}
+
+func diagnosticsProjects(t *testing.T) []Project {
+	SearchPath = "/services"
+	return []Project{
+		{Name: "web", Path: "/services/web", Valid: ConfigValid},
+		{Name: "db", Path: "/services/db", Valid: ConfigInvalid, Diagnostics: parseTestDiagnostics(t).Diagnostics},
+		{Name: "queue", Path: "/services/queue", Valid: ConfigTimeout, Output: "terraform validate timed out after 5m0s"},
+		{Name: "cache", Path: "/services/cache", Valid: ConfigUnknown},
+	}
+}
+
+func TestRenderJUnitReport(t *testing.T) {
+	report, err := renderJUnitReport(diagnosticsProjects(t), testTime)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(report), &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v", err)
+	}
+	if suites.Tests != 4 || suites.Failures != 1 || suites.Errors != 1 || suites.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", suites)
+	}
+
+	cases := suites.Suites[0].Cases
+	if cases[1].Name != "db" || cases[1].Failure == nil || !strings.Contains(cases[1].Failure.Text, "on main.tf line 3") {
+		t.Errorf("Expected a failure for db, got %+v", cases[1])
+	}
+	if !strings.Contains(cases[1].SystemOut, "Deprecated attribute") {
+		t.Errorf("Expected the warning in the system output, got %q", cases[1].SystemOut)
+	}
+	if cases[2].Error == nil || cases[3].Skipped == nil {
+		t.Errorf("Expected an error for queue and a skipped cache, got %+v", cases[2:])
+	}
+}
+
+func TestRenderSARIFReport(t *testing.T) {
+	report, err := renderSARIFReport(diagnosticsProjects(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var sarif sarifLog
+	if err := json.Unmarshal([]byte(report), &sarif); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	results := sarif.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("Expected one result per diagnostic, got %d", len(results))
+	}
+
+	location := results[0].Locations[0].PhysicalLocation
+	if results[0].Level != SeverityError || location.ArtifactLocation.URI != "db/main.tf" {
+		t.Errorf("Unexpected result: %+v", results[0])
+	}
+	if location.Region == nil || location.Region.StartLine != 3 || location.Region.EndColumn != 6 {
+		t.Errorf("Unexpected region: %+v", location.Region)
+	}
+	if results[1].Level != SeverityWarning || results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI != "db" {
+		t.Errorf("Unexpected result: %+v", results[1])
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// snippetContext is the number of lines shown around a diagnostic's range.
+const snippetContext = 2
+
+type DiagnosticsModel struct {
+	path        string
+	name        string
+	diagnostics []Diagnostic
+	snippets    []Snippet
+	status      string
+	cursor      int
+	offset      int
+	width       int
+	height      int
+}
+
+// SnippetLine is a line of source code. Start and End are the rune offsets of
+// the highlighted part of the line, if any.
+type SnippetLine struct {
+	Number int
+	Text   string
+	Start  int
+	End    int
+}
+
+// Snippet is the source code of a diagnostic's range, or the error that kept
+// it from being read.
+type Snippet struct {
+	Lines []SnippetLine
+	Err   error
+}
+
+func createDiagnosticsModel(project Project, width int, height int) DiagnosticsModel {
+	return DiagnosticsModel{
+		path:        project.Path,
+		name:        project.Name,
+		diagnostics: project.Diagnostics,
+		snippets:    readSnippets(project.Path, project.Diagnostics),
+		width:       width,
+		height:      height,
+	}
+}
+
+func (m *DiagnosticsModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.offset = max(min(m.offset, m.cursor), m.cursor-m.listHeight()+1)
+}
+
+func (m *DiagnosticsModel) setDiagnostics(diagnostics []Diagnostic) {
+	m.diagnostics = diagnostics
+	m.snippets = readSnippets(m.path, diagnostics)
+	m.cursor = min(m.cursor, max(len(diagnostics)-1, 0))
+	m.offset = min(m.offset, m.cursor)
+}
+
+func (m *DiagnosticsModel) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.diagnostics)-1))
+	m.status = ""
+
+	listHeight := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+}
+
+func (m *DiagnosticsModel) listHeight() int {
+	return max(min(len(m.diagnostics), m.height/3), 1)
+}
+
+func (m *DiagnosticsModel) highlighted() (Diagnostic, bool) {
+	if m.cursor >= len(m.diagnostics) {
+		return Diagnostic{}, false
+	}
+	return m.diagnostics[m.cursor], true
+}
+
+// readSnippet reads the lines of a diagnostic's range from the project, plus
+// a few lines of context on either side.
+func readSnippet(dir string, r *SourceRange, context int) ([]SnippetLine, error) {
+	file, err := os.Open(filepath.Join(dir, r.Filename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []SnippetLine{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		if number < r.Start.Line-context {
+			continue
+		}
+		if number > r.End.Line+context {
+			break
+		}
+
+		text := strings.ReplaceAll(scanner.Text(), "\t", "  ")
+		line := SnippetLine{Number: number, Text: text, Start: -1, End: -1}
+		if number >= r.Start.Line && number <= r.End.Line {
+			line.Start, line.End = 0, len([]rune(text))
+			if number == r.Start.Line {
+				line.Start = min(max(r.Start.Column-1, 0), line.End)
+			}
+			if number == r.End.Line {
+				line.End = max(min(r.End.Column-1, line.End), line.Start)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// readSnippets reads the snippets of all diagnostics once, so that rendering
+// the view doesn't read the files again on every update.
+func readSnippets(dir string, diagnostics []Diagnostic) []Snippet {
+	snippets := make([]Snippet, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Range != nil {
+			snippets[i].Lines, snippets[i].Err = readSnippet(dir, diagnostic.Range, snippetContext)
+		}
+	}
+	return snippets
+}
+
+func severityStyle(severity string) lipgloss.Style {
+	if severity == SeverityWarning {
+		return warning
+	}
+	return errorStyle
+}
+
+func severitySymbol(severity string) string {
+	if severity == SeverityWarning {
+		return "!"
+	}
+	return currentTheme.Symbols.Invalid
+}
+
+func renderSnippet(lines []SnippetLine, style lipgloss.Style) string {
+	numberWidth := len(fmt.Sprint(lines[len(lines)-1].Number))
+	rendered := []string{}
+	for _, line := range lines {
+		text := line.Text
+		if line.Start >= 0 {
+			runes := []rune(text)
+			highlight := string(runes[line.Start:line.End])
+			if highlight == "" {
+				highlight = " "
+			}
+			text = string(runes[:line.Start]) + style.Copy().Underline(true).Render(highlight) + string(runes[line.End:])
+		}
+		number := tableDate.Render(fmt.Sprintf("%*d │", numberWidth, line.Number))
+		rendered = append(rendered, fmt.Sprintf("   %s %s", number, text))
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// openInEditor suspends the UI while the highlighted diagnostic's file is open
+// in $EDITOR.
+func (m *DiagnosticsModel) openInEditor() tea.Cmd {
+	diagnostic, ok := m.highlighted()
+	if !ok || diagnostic.Range == nil {
+		m.status = warning.Render("This diagnostic does not refer to a file")
+		return nil
+	}
+
+	file := filepath.Join(m.path, diagnostic.Range.Filename)
+	return execInProject(m.path, editorCommand(userEditor(), file, diagnostic.Range.Start.Line))
+}
+
+func (m *DiagnosticsModel) diagnosticsHeader() string {
+	title := outputTitle.Render(fmt.Sprintf("Diagnostics: %s", m.name))
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m *DiagnosticsModel) renderDiagnostics() string {
+	body := strings.Builder{}
+	body.WriteString(m.diagnosticsHeader())
+	body.WriteString("\n\n")
+
+	if len(m.diagnostics) == 0 {
+		body.WriteString(success.Render(" No errors or warnings") + "\n")
+	}
+
+	end := min(m.offset+m.listHeight(), len(m.diagnostics))
+	for i := m.offset; i < end; i++ {
+		diagnostic := m.diagnostics[i]
+		symbol := severityStyle(diagnostic.Severity).Render(severitySymbol(diagnostic.Severity))
+		line := fmt.Sprintf(" %s %s  %s", symbol, diagnostic.Summary, tableDate.Render(diagnostic.location()))
+		line = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+		if i == m.cursor {
+			line = tableHighlighted.Render(line)
+		}
+		body.WriteString(line + "\n")
+	}
+
+	if diagnostic, ok := m.highlighted(); ok {
+		body.WriteString("\n")
+		style := severityStyle(diagnostic.Severity)
+		body.WriteString(" " + style.Copy().Bold(true).Render(formatDiagnostic(Diagnostic{
+			Severity: diagnostic.Severity,
+			Summary:  diagnostic.Summary,
+		})) + "\n")
+
+		if diagnostic.Detail != "" {
+			detail := lipgloss.NewStyle().Width(m.width - 2).Render(diagnostic.Detail)
+			body.WriteString("\n" + lipgloss.NewStyle().PaddingLeft(1).Render(detail) + "\n")
+		}
+
+		if diagnostic.Range != nil {
+			body.WriteString("\n " + tableDate.Render(fmt.Sprintf("on %s:", diagnostic.location())) + "\n")
+			snippet := m.snippets[m.cursor]
+			if snippet.Err != nil {
+				body.WriteString(" " + errorStyle.Render(fmt.Sprintf("Could not read source: %s", snippet.Err)) + "\n")
+			} else if len(snippet.Lines) > 0 {
+				body.WriteString(renderSnippet(snippet.Lines, style) + "\n")
+			}
+		}
+	}
+
+	body.WriteString("\n " + m.status + "\n")
+	return body.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadSnippet(t *testing.T) {
+	dir := t.TempDir()
+	source := "terraform {}\n\nresource \"aws_instance\" \"web\" {\n  foo = 1\n}\n\noutput \"x\" {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &SourceRange{
+		Filename: "main.tf",
+		Start:    SourcePosition{Line: 4, Column: 3},
+		End:      SourcePosition{Line: 4, Column: 6},
+	}
+	lines, err := readSnippet(dir, r, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []SnippetLine{
+		{3, `resource "aws_instance" "web" {`, -1, -1},
+		{4, "  foo = 1", 2, 5},
+		{5, "}", -1, -1},
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("Expected %v, got %v", want, lines)
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		if _, err := readSnippet(dir, &SourceRange{Filename: "missing.tf"}, 1); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("Snippets are read once", func(t *testing.T) {
+		project := Project{Path: dir, Name: "web", Diagnostics: []Diagnostic{{Severity: SeverityError, Summary: "Bad", Range: r}}}
+		m := createDiagnosticsModel(project, 80, 30)
+		if err := os.Remove(filepath.Join(dir, "main.tf")); err != nil {
+			t.Fatal(err)
+		}
+		if view := m.renderDiagnostics(); !strings.Contains(view, "foo = 1") {
+			t.Errorf("Expected the snippet read with the model, got %q", view)
+		}
+
+		m.setDiagnostics(project.Diagnostics)
+		if view := m.renderDiagnostics(); !strings.Contains(view, "Could not read source") {
+			t.Errorf("Expected the snippet read again with new diagnostics, got %q", view)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Terraform subcommands that accept -input=false.
+var inputSubcommands = []string{"apply", "destroy", "import", "init", "plan", "refresh"}
+
+// Terraform subcommands that write state. Resources can take a long time to
+// change without any output, and once interrupted Terraform needs to finish
+// its in-flight operations, so these are never stopped for being idle and get
+// a much longer grace period before they are killed.
+var stateSubcommands = []string{"apply", "destroy"}
+
+const (
+	idleCheckInterval   = time.Second
+	interruptGrace      = 10 * time.Second
+	stateInterruptGrace = 30 * time.Minute
+)
+
+type RunTimeoutError struct {
+	Command string
+	After   time.Duration
+	Idle    bool
+}
+
+func (e RunTimeoutError) Error() string {
+	if e.Idle {
+		return fmt.Sprintf("terraform %s timed out: no output for %s", e.Command, e.After)
+	}
+	return fmt.Sprintf("terraform %s timed out after %s", e.Command, e.After)
+}
+
+// activity records when a running command last wrote any output.
+type activity struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (a *activity) touch() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.last = time.Now()
+}
+
+func (a *activity) idle() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Since(a.last)
+}
+
+type activityWriter struct {
+	w        io.Writer
+	activity *activity
+}
+
+func (w *activityWriter) Write(p []byte) (int, error) {
+	w.activity.touch()
+	return w.w.Write(p)
+}
+
+func executeTerraform(dir string, args ...string) (string, error) {
+	out := bytes.Buffer{}
+	err := runTerraform(dir, &out, &out, args...)
+	return maskedOutput(dir, out.String()), err
+}
+
+// runTerraform runs Terraform non-interactively with the binary resolved for
+// the project and the variables of its profile, or returns its
+// VersionMismatchError without running anything. The command is stopped with a
+// RunTimeoutError if it exceeds its configured timeout or stops producing
+// output for longer than the idle timeout; anything it wrote until then is kept
+// in stdout/stderr.
+func runTerraform(dir string, stdout io.Writer, stderr io.Writer, args ...string) error {
+	args = disableInput(args)
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timeout := Settings.timeout(subcommand)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	binary, err := terraformBinaries.get(dir)
+	if err != nil {
+		return err
+	}
+	env, _, err := profileEnv(dir)
+	if err != nil {
+		return err
+	}
+	if Debug {
+		log.Printf("Running %s %s in %s with %s", binary, strings.Join(args, " "), dir, strings.Join(envNames(env), " "))
+	}
+
+	cmd := newTerraformCmd(ctx, binary, dir, env, args...)
+	lastOutput := &activity{last: time.Now()}
+	stdoutWriter := &activityWriter{stdout, lastOutput}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stdoutWriter
+	if stderr != stdout {
+		cmd.Stderr = &activityWriter{stderr, lastOutput}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	idleTimeout := Settings.idleTimeout(subcommand)
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	idle := false
+	for {
+		select {
+		case err := <-done:
+			switch {
+			case idle:
+				return RunTimeoutError{Command: subcommand, After: idleTimeout, Idle: true}
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				return RunTimeoutError{Command: subcommand, After: timeout}
+			}
+			return err
+
+		case <-ticker.C:
+			if idleTimeout > 0 && !idle && lastOutput.idle() > idleTimeout {
+				idle = true
+				cancel()
+			}
+		}
+	}
+}
+
+func newTerraformCmd(ctx context.Context, binary string, dir string, env []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+	cmd.Env = slices.Concat(os.Environ(), env, []string{"TF_INPUT=0"})
+
+	// give Terraform the chance to release state locks before it is killed
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = interruptGrace
+	if len(args) > 0 && slices.Contains(stateSubcommands, args[0]) {
+		cmd.WaitDelay = stateInterruptGrace
+	}
+	return cmd
+}
+
+// disableInput adds -input=false to subcommands that would otherwise prompt
+// for missing variables.
+func disableInput(args []string) []string {
+	if len(args) == 0 || !slices.Contains(inputSubcommands, args[0]) {
+		return args
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-input") {
+			return args
+		}
+	}
+	return slices.Concat(args[:1], []string{"-input=false"}, args[1:])
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDisableInput(t *testing.T) {
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"plan"}, []string{"plan", "-input=false"}},
+		{[]string{"apply", "-auto-approve"}, []string{"apply", "-input=false", "-auto-approve"}},
+		{[]string{"import", "-input=false", "a.b", "id"}, []string{"import", "-input=false", "a.b", "id"}},
+		{[]string{"validate"}, []string{"validate"}},
+		{[]string{"state", "list"}, []string{"state", "list"}},
+	}
+
+	for _, c := range cases {
+		if got := disableInput(c.args); !slices.Equal(got, c.want) {
+			t.Errorf("Expected %v, got %v", c.want, got)
+		}
+	}
+}
+
+func TestApplyGracePeriod(t *testing.T) {
+	if cmd := newTerraformCmd(context.Background(), TerraformBinary, ".", nil, "apply", "-auto-approve"); cmd.WaitDelay != stateInterruptGrace {
+		t.Errorf("Expected apply to be killed after %s, got %s", stateInterruptGrace, cmd.WaitDelay)
+	}
+	if cmd := newTerraformCmd(context.Background(), TerraformBinary, ".", nil, "plan"); cmd.WaitDelay != interruptGrace {
+		t.Errorf("Expected plan to be killed after %s, got %s", interruptGrace, cmd.WaitDelay)
+	}
+}
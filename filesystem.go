@@ -1,14 +1,30 @@
 package main
 
 import (
+	"encoding/json"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-const TerraformDir = ".terraform"
+const (
+	TerraformDir     = ".terraform"
+	DefaultWorkspace = "default"
+	LocalBackend     = "local"
+)
+
+// backendState is the subset of .terraform/terraform.tfstate (or a local
+// terraform.tfstate) that describes how the project was initialized.
+type backendState struct {
+	TerraformVersion string `json:"terraform_version"`
+	Backend          *struct {
+		Type string `json:"type"`
+	} `json:"backend"`
+}
 
 func isTerraformProject(filesystem fs.FS, d fs.DirEntry, path string) (bool, error) {
 	if !d.IsDir() {
@@ -28,6 +44,32 @@ func isTerraformProject(filesystem fs.FS, d fs.DirEntry, path string) (bool, err
 	return false, nil
 }
 
+func loadProjectMetadata(filesystem fs.FS, dir string, project *Project) {
+	project.Workspace = DefaultWorkspace
+	if data, err := fs.ReadFile(filesystem, path.Join(dir, TerraformDir, "environment")); err == nil {
+		project.Workspace = strings.TrimSpace(string(data))
+	}
+
+	project.Backend = LocalBackend
+	for _, file := range []string{path.Join(dir, TerraformDir, "terraform.tfstate"), path.Join(dir, "terraform.tfstate")} {
+		data, err := fs.ReadFile(filesystem, file)
+		if err != nil {
+			continue
+		}
+
+		var state backendState
+		if err := json.Unmarshal(data, &state); err != nil {
+			continue
+		}
+		if state.Backend != nil && state.Backend.Type != "" {
+			project.Backend = state.Backend.Type
+		}
+		if project.TerraformVersion == "" {
+			project.TerraformVersion = state.TerraformVersion
+		}
+	}
+}
+
 func findAllTerraformProjects(filesystem fs.FS) ([]Project, error) {
 	projects := []Project{}
 
@@ -55,6 +97,8 @@ func findAllTerraformProjects(filesystem fs.FS) ([]Project, error) {
 				Output:       "Run Terraform plan to view output",
 				Valid:        "?",
 			}
+			loadProjectMetadata(filesystem, path, &project)
+			loadRequiredVersion(filesystem, path, &project)
 			projects = append(projects, project)
 		}
 
@@ -64,8 +108,20 @@ func findAllTerraformProjects(filesystem fs.FS) ([]Project, error) {
 	return projects, nil
 }
 
-func refreshProjects() tea.Msg {
+// loadProjects finds the projects below the search path and resolves the
+// Terraform binary and profile each of them runs with.
+func loadProjects() ([]Project, error) {
 	projects, err := findAllTerraformProjects(os.DirFS(SearchPath))
+	if err != nil {
+		return nil, err
+	}
+	resolveVersions(projects)
+	resolveProfiles(projects)
+	return projects, nil
+}
+
+func refreshProjects() tea.Msg {
+	projects, err := loadProjects()
 	if err != nil {
 		return ErrMsg{err}
 	}
@@ -111,3 +111,31 @@ func assertSameDirectories(t *testing.T, got []Project, want []fs.DirEntry) {
 		}
 	}
 }
+
+func TestLoadProjectMetadata(t *testing.T) {
+	t.Run("reads workspace, backend and version", func(t *testing.T) {
+		filesystem := fstest.MapFS{
+			"project/.terraform/environment":       {Data: []byte("prod\n")},
+			"project/.terraform/terraform.tfstate": {Data: []byte(`{"version":3,"terraform_version":"1.5.7","backend":{"type":"s3"}}`)},
+		}
+
+		var project Project
+		loadProjectMetadata(filesystem, "project", &project)
+		if project.Workspace != "prod" || project.Backend != "s3" || project.TerraformVersion != "1.5.7" {
+			t.Errorf("Unexpected metadata: %+v", project)
+		}
+	})
+
+	t.Run("defaults for local state", func(t *testing.T) {
+		filesystem := fstest.MapFS{
+			"project/.terraform/providers": {Mode: fs.ModeDir},
+			"project/terraform.tfstate":    {Data: []byte(`{"version":4,"terraform_version":"1.6.0"}`)},
+		}
+
+		var project Project
+		loadProjectMetadata(filesystem, "project", &project)
+		if project.Workspace != DefaultWorkspace || project.Backend != LocalBackend || project.TerraformVersion != "1.6.0" {
+			t.Errorf("Unexpected metadata: %+v", project)
+		}
+	})
+}
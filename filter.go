@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter for the projects table. A project matches if it
+// matches every term of at least one of the alternatives, which are separated
+// by "or" in the filter text.
+type Query [][]FilterTerm
+
+// FilterTerm is a single condition of a query, such as "destroy>0". A term
+// without a field matches the name, path or workspace.
+type FilterTerm struct {
+	Field  string
+	Op     string
+	Value  string
+	Number int
+	Age    time.Duration
+	Negate bool
+}
+
+const (
+	filterText      = ""
+	filterName      = "name"
+	filterPath      = "path"
+	filterWorkspace = "workspace"
+	filterBackend   = "backend"
+	filterVersion   = "version"
+	filterRequired  = "required"
+	filterResolved  = "resolved"
+	filterProfile   = "profile"
+	filterValid     = "valid"
+	filterFormatted = "formatted"
+	filterStatus    = "status"
+	filterAdd       = "add"
+	filterChange    = "change"
+	filterDestroy   = "destroy"
+	filterModified  = "modified"
+	filterRun       = "run"
+)
+
+var (
+	filterOperators  = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+	textFilterFields = []string{filterName, filterPath, filterWorkspace, filterBackend, filterVersion, filterRequired, filterResolved, filterProfile}
+	countFields      = []string{filterAdd, filterChange, filterDestroy}
+	ageFields        = []string{filterModified, filterRun}
+	statusValues     = []string{"changes", "clean", "error", "drift", "locked", "timeout", "mismatch"}
+	validValues      = map[string]string{
+		"true":    ConfigValid,
+		"false":   ConfigInvalid,
+		"unknown": ConfigUnknown,
+		"timeout": ConfigTimeout,
+	}
+	ageUnits = map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+)
+
+// parseQuery parses filter text such as "destroy>0 workspace:prod". Terms are
+// separated by spaces and must all match, "or" separates alternatives and a
+// leading "-" negates a term.
+func parseQuery(text string) (Query, error) {
+	query := Query{}
+	terms := []FilterTerm{}
+	for _, word := range strings.Fields(text) {
+		if strings.EqualFold(word, "or") {
+			if len(terms) > 0 {
+				query = append(query, terms)
+			}
+			terms = []FilterTerm{}
+			continue
+		}
+
+		term, err := parseFilterTerm(word)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > 0 {
+		query = append(query, terms)
+	}
+	return query, nil
+}
+
+func parseFilterTerm(word string) (FilterTerm, error) {
+	term := FilterTerm{}
+	if len(word) > 1 && (word[0] == '-' || word[0] == '!') {
+		term.Negate = true
+		word = word[1:]
+	}
+
+	index := strings.IndexAny(word, ":=<>!")
+	if index <= 0 {
+		term.Value = strings.ToLower(word)
+		return term, nil
+	}
+
+	term.Field = strings.ToLower(word[:index])
+	for _, op := range filterOperators {
+		if strings.HasPrefix(word[index:], op) {
+			term.Op = op
+			break
+		}
+	}
+	term.Value = strings.ToLower(word[index+len(term.Op):])
+	if term.Op == "" || term.Value == "" {
+		return term, fmt.Errorf("%q needs a value", word)
+	}
+
+	equality := term.Op == ":" || term.Op == "=" || term.Op == "!="
+	switch {
+	case slices.Contains(textFilterFields, term.Field):
+		if !equality {
+			return term, fmt.Errorf("%s can only be compared with : or !=", term.Field)
+		}
+
+	case term.Field == filterValid, term.Field == filterFormatted:
+		if _, ok := validValues[term.Value]; !ok || !equality {
+			return term, fmt.Errorf("%s must be one of true, false, unknown or timeout", term.Field)
+		}
+
+	case term.Field == filterStatus:
+		if !slices.Contains(statusValues, term.Value) || !equality {
+			return term, fmt.Errorf("status must be one of %s", strings.Join(statusValues, ", "))
+		}
+
+	case slices.Contains(countFields, term.Field):
+		number, err := strconv.Atoi(term.Value)
+		if err != nil {
+			return term, fmt.Errorf("%s must be compared with a number", term.Field)
+		}
+		term.Number = number
+
+	case slices.Contains(ageFields, term.Field):
+		age, err := parseAge(term.Value)
+		if err != nil || equality {
+			return term, fmt.Errorf("%s must be compared with <, <=, > or >= and an age such as 2d", term.Field)
+		}
+		term.Age = age
+
+	default:
+		return term, fmt.Errorf("unknown filter field %q", term.Field)
+	}
+
+	if term.Op == "!=" && !slices.Contains(countFields, term.Field) {
+		term.Op = ":"
+		term.Negate = !term.Negate
+	}
+	return term, nil
+}
+
+// parseAge parses ages such as "30m", "12h", "2d" or "1w".
+func parseAge(text string) (time.Duration, error) {
+	if unit, ok := ageUnits[text[len(text)-1:]]; ok {
+		if number, err := strconv.Atoi(text[:len(text)-1]); err == nil {
+			return time.Duration(number) * unit, nil
+		}
+	}
+	return time.ParseDuration(text)
+}
+
+func (q Query) matches(project Project, now time.Time) bool {
+	if len(q) == 0 {
+		return true
+	}
+	for _, terms := range q {
+		matched := true
+		for _, term := range terms {
+			if term.matches(project, now) == term.Negate {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (t FilterTerm) matches(project Project, now time.Time) bool {
+	switch t.Field {
+	case filterText:
+		return matchText(project.Name, t.Value) ||
+			matchText(relativeProjectPath(project.Path), t.Value) ||
+			matchText(project.Workspace, t.Value)
+
+	case filterName, filterPath, filterWorkspace, filterBackend, filterVersion, filterRequired, filterResolved,
+		filterProfile:
+		return matchText(projectText(project, t.Field), t.Value)
+
+	case filterValid:
+		return project.Valid == validValues[t.Value]
+
+	case filterFormatted:
+		return project.Formatted == validValues[t.Value]
+
+	case filterStatus:
+		return projectStatus(project) == t.Value
+
+	case filterAdd:
+		return planned(project) && compareCount(project.PlanChanges.Add, t.Op, t.Number)
+
+	case filterChange:
+		return planned(project) && compareCount(project.PlanChanges.Change, t.Op, t.Number)
+
+	case filterDestroy:
+		return planned(project) && compareCount(project.PlanChanges.Destroy, t.Op, t.Number)
+
+	case filterModified:
+		return compareAge(project.LastModified, now, t.Op, t.Age)
+
+	case filterRun:
+		return compareAge(project.LastRun, now, t.Op, t.Age)
+	}
+	return false
+}
+
+func projectText(project Project, field string) string {
+	switch field {
+	case filterName:
+		return project.Name
+	case filterPath:
+		return relativeProjectPath(project.Path)
+	case filterWorkspace:
+		return project.Workspace
+	case filterBackend:
+		return project.Backend
+	case filterVersion:
+		return project.TerraformVersion
+	case filterRequired:
+		return formatRequiredVersion(project)
+	case filterResolved:
+		return project.ResolvedVersion
+	case filterProfile:
+		return project.Profile
+	}
+	return ""
+}
+
+// matchText matches a glob pattern against the whole text, or looks for the
+// value anywhere in the text if it has no wildcards. Both ignore case.
+func matchText(text string, value string) bool {
+	text = strings.ToLower(text)
+	if strings.ContainsAny(value, "*?[") {
+		matched, _ := filepath.Match(value, text)
+		return matched
+	}
+	return strings.Contains(text, value)
+}
+
+// projectStatus summarizes the last plan of a project for "status:" terms.
+func projectStatus(project Project) string {
+	if project.VersionMismatch != nil {
+		return "mismatch"
+	}
+
+	switch TerraformError(project.PlanChanges.Add) {
+	case PlanError:
+		return "error"
+	case DriftError:
+		return "drift"
+	case LockError:
+		return "locked"
+	case TimeoutError:
+		return "timeout"
+	}
+
+	changes := project.PlanChanges
+	if changes.Add > 0 || changes.Change > 0 || changes.Destroy > 0 {
+		return "changes"
+	}
+	if project.LastAction == Plan || project.LastAction == Apply {
+		return "clean"
+	}
+	return ""
+}
+
+// planned reports whether the last plan of a project succeeded, so that its
+// change counts can be compared.
+func planned(project Project) bool {
+	status := projectStatus(project)
+	return status == "changes" || status == "clean"
+}
+
+// compareCount compares a planned change count. Failed plans never match.
+func compareCount(count int, op string, number int) bool {
+	if count < 0 {
+		return false
+	}
+	switch op {
+	case "!=":
+		return count != number
+	case ">":
+		return count > number
+	case ">=":
+		return count >= number
+	case "<":
+		return count < number
+	case "<=":
+		return count <= number
+	default:
+		return count == number
+	}
+}
+
+// compareAge compares how long ago something happened, so "modified<2d"
+// matches projects changed in the last two days. A zero time never matches.
+func compareAge(t time.Time, now time.Time, op string, age time.Duration) bool {
+	if t.IsZero() {
+		return false
+	}
+	elapsed := now.Sub(t)
+	switch op {
+	case ">":
+		return elapsed > age
+	case ">=":
+		return elapsed >= age
+	case "<":
+		return elapsed < age
+	case "<=":
+		return elapsed <= age
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	valid := []string{"", "api", "destroy>0", "valid:false", "status:drift", "path:services/*", "-workspace:prod", "modified<2d", "run>=12h", "add=1 or change!=0"}
+	for _, text := range valid {
+		if _, err := parseQuery(text); err != nil {
+			t.Errorf("Unexpected error for %q: %v", text, err)
+		}
+	}
+
+	invalid := []string{"destroy>", "destroy>many", "valid:maybe", "formatted:yes", "status:broken", "owner:me", "name>a", "modified=2d", "modified:2d", "run<soon"}
+	for _, text := range invalid {
+		if _, err := parseQuery(text); err == nil {
+			t.Errorf("Expected an error for %q", text)
+		}
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	defer func(path string) { SearchPath = path }(SearchPath)
+	SearchPath = "/repo"
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	projects := map[string]Project{
+		"api": {
+			Name: "api", Path: "/repo/services/api", Workspace: "prod", Valid: ConfigValid,
+			LastAction: Plan, PlanChanges: TerraformChanges{1, 0, 2}, LastModified: now.Add(-time.Hour),
+		},
+		"db": {
+			Name: "db", Path: "/repo/services/db", Workspace: "staging", Valid: ConfigInvalid,
+			LastAction: Plan, PlanChanges: TerraformChanges{DriftError.Value(), DriftError.Value(), DriftError.Value()},
+			LastModified: now.Add(-72 * time.Hour),
+		},
+		"dns": {
+			Name: "dns", Path: "/repo/network/dns", Workspace: "prod", Valid: ConfigValid, Formatted: ConfigInvalid,
+			LastAction: Plan, LastModified: now.Add(-30 * 24 * time.Hour),
+		},
+		"web": {
+			Name: "web", Path: "/repo/apps/web", Workspace: "dev", LastAction: Validate,
+		},
+	}
+
+	cases := map[string][]string{
+		"":                            {"api", "db", "dns", "web"},
+		"services":                    {"api", "db"},
+		"destroy>0":                   {"api"},
+		"destroy=0":                   {"dns"},
+		"add!=0":                      {"api"},
+		"add!=1":                      {"dns"},
+		"valid:false":                 {"db"},
+		"formatted:false":             {"dns"},
+		"status:drift":                {"db"},
+		"status:clean":                {"dns"},
+		"path:services/*":             {"api", "db"},
+		"workspace:prod destroy>0":    {"api"},
+		"-workspace:prod":             {"db", "web"},
+		"workspace!=prod":             {"db", "web"},
+		"modified<2d":                 {"api"},
+		"modified>1w or status:drift": {"db", "dns"},
+	}
+	for text, want := range cases {
+		query, err := parseQuery(text)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", text, err)
+		}
+		for _, name := range []string{"api", "db", "dns", "web"} {
+			expected := false
+			for _, w := range want {
+				expected = expected || w == name
+			}
+			if got := query.matches(projects[name], now); got != expected {
+				t.Errorf("Expected %q to match %s: %t, got %t", text, name, expected, got)
+			}
+		}
+	}
+}
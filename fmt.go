@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FmtResult is the formatting check of one project: the diff `terraform fmt`
+// would apply, or the error that kept it from checking the project.
+type FmtResult struct {
+	Path string
+	Name string
+	Diff string
+	Err  error
+}
+
+type (
+	UpdateFmtMsg Project
+
+	// FmtCheckedMsg opens the fmt view. If Write is set, it also asks to
+	// format the projects with changes.
+	FmtCheckedMsg struct {
+		Results []FmtResult
+		Write   bool
+	}
+)
+
+// executeFmtCheck runs `terraform fmt -check -diff` and returns the diff. A
+// non-empty diff is not an error; the error is only set if the files could not
+// be checked, e.g. because of a syntax error.
+func executeFmtCheck(dir string) (string, error) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	err := runTerraform(dir, &stdout, &stderr, Fmt.String(), "-check", "-diff", "-list=false", "-no-color")
+	if _, exited := err.(*exec.ExitError); exited && stderr.Len() == 0 {
+		err = nil
+	} else if exited {
+		err = fmt.Errorf("%s", strings.TrimSpace(maskedOutput(dir, stderr.String())))
+	}
+	return maskedOutput(dir, stdout.String()), err
+}
+
+// checkFmt checks the formatting of a project and records it in the project.
+func checkFmt(project *Project) FmtResult {
+	diff, err := executeFmtCheck(project.Path)
+	project.FmtDiff = diff
+	switch {
+	case err != nil:
+		if _, timedOut := err.(RunTimeoutError); timedOut {
+			project.Formatted = ConfigTimeout
+		} else {
+			project.Formatted = ConfigUnknown
+		}
+	case diff != "":
+		project.Formatted = ConfigInvalid
+	default:
+		project.Formatted = ConfigValid
+	}
+	return FmtResult{Path: project.Path, Name: project.Name, Diff: diff, Err: err}
+}
+
+// runValidateAndFmtCheck checks the formatting of a project before validating
+// it, so that refreshing never updates a project from two commands at once.
+func runValidateAndFmtCheck(project *Project) tea.Cmd {
+	validate := runValidate(project)
+	return func() tea.Msg {
+		checkFmt(project)
+		return validate()
+	}
+}
+
+// runFmtCheck checks the formatting of the projects for the fmt view.
+func runFmtCheck(projects []*Project, write bool) tea.Cmd {
+	return func() tea.Msg {
+		results := []FmtResult{}
+		for _, project := range projects {
+			results = append(results, checkFmt(project))
+		}
+		return FmtCheckedMsg{Results: results, Write: write}
+	}
+}
+
+// runFmt rewrites the project's files with `terraform fmt`.
+func runFmt(project *Project) tea.Cmd {
+	return func() tea.Msg {
+		output, err := executeTerraform(project.Path, Fmt.String(), "-no-color")
+		project.LastAction = Fmt
+		if err != nil {
+			project.Formatted = ConfigUnknown
+			project.Output = withRunError(output, err)
+			return UpdateFmtMsg(*project)
+		}
+
+		project.Formatted = ConfigValid
+		project.FmtDiff = ""
+		project.Output = output
+		if output == "" {
+			project.Output = "All files are formatted."
+		}
+		return UpdateFmtMsg(*project)
+	}
+}
+
+func formatFmt(status string) string {
+	if status == "" {
+		return tableDate.Render("-")
+	}
+	return formatValid(status)
+}
+
+// fmtSummary describes the results of a formatting check in one line.
+func fmtSummary(results []FmtResult) string {
+	changed, failed := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.Diff != "":
+			changed++
+		}
+	}
+
+	summary := fmt.Sprintf("fmt: %d of %d projects need formatting", changed, len(results))
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d could not be checked", failed)
+	}
+	return summary
+}
+
+// colorDiff colors the added and removed lines of a unified diff.
+func colorDiff(diff string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = outputInfo.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = tableDate.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = success.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = errorStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FmtModel shows the changes `terraform fmt` would make to one or more
+// projects, before any files are written.
+type FmtModel struct {
+	results  []FmtResult
+	viewport viewport.Model
+	status   string
+	width    int
+	height   int
+}
+
+func createFmtModel(results []FmtResult, width int, height int) FmtModel {
+	m := FmtModel{results: results, viewport: viewport.New(width, 1)}
+	m.viewport.KeyMap = mainKeys.viewportKeyMap()
+	m.setSize(width, height)
+	m.renderContent()
+	return m
+}
+
+func (m *FmtModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-5, 1)
+}
+
+// changed returns the paths of the projects that need formatting.
+func (m *FmtModel) changed() []string {
+	paths := []string{}
+	for _, result := range m.results {
+		if result.Diff != "" {
+			paths = append(paths, result.Path)
+		}
+	}
+	return paths
+}
+
+// setResult updates a project after it was formatted.
+func (m *FmtModel) setResult(project Project) {
+	for i, result := range m.results {
+		if result.Path == project.Path {
+			m.results[i].Diff = project.FmtDiff
+		}
+	}
+	m.renderContent()
+}
+
+func (m *FmtModel) renderContent() {
+	sections := []string{}
+	for _, result := range m.results {
+		section := outputTitle.Render(result.Name) + "\n\n"
+		switch {
+		case result.Err != nil:
+			section += errorStyle.Render(result.Err.Error())
+		case result.Diff == "":
+			section += success.Render("Formatted")
+		default:
+			section += colorDiff(result.Diff)
+		}
+		sections = append(sections, section)
+	}
+	m.viewport.SetContent(strings.Join(sections, "\n\n") + "\n")
+}
+
+func (m *FmtModel) fmtHeader() string {
+	name := fmt.Sprintf("%d projects", len(m.results))
+	if len(m.results) == 1 {
+		name = m.results[0].Name
+	}
+	title := outputTitle.Render(fmt.Sprintf("Formatting: %s", name))
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m *FmtModel) renderFmt() string {
+	body := strings.Builder{}
+	body.WriteString(m.fmtHeader())
+	body.WriteString("\n\n")
+	body.WriteString(m.viewport.View())
+	body.WriteString("\n " + m.status + "\n")
+	return body.String()
+}
+
+// fmtTargets returns the projects to format: the selected projects or, if
+// none are selected, the highlighted one.
+func (m *MainModel) fmtTargets() []*Project {
+	targets := []*Project{}
+	for _, path := range m.actionTargets() {
+		if project := matchProjectInMemory(path, &m.projects); project != nil {
+			targets = append(targets, project)
+		}
+	}
+	return targets
+}
+
+// confirmFmt asks before formatting the projects in the fmt view that have
+// changes, which are shown above the confirmation.
+func (m *MainModel) confirmFmt() {
+	targets := []*Project{}
+	for _, path := range m.fmt.changed() {
+		if project := matchProjectInMemory(path, &m.projects); project != nil {
+			targets = append(targets, project)
+		}
+	}
+	if len(targets) == 0 {
+		m.fmt.status = "Nothing to format"
+		return
+	}
+
+	m.fmt.status = ""
+	message := fmt.Sprintf("This will run `terraform fmt` and rewrite the files of %d projects", len(targets))
+	m.confirm(message, func(m *MainModel) tea.Cmd {
+		m.message = fmt.Sprintf("Terraform Fmt: %d projects", len(targets))
+		batchArgs := []tea.Cmd{}
+		for _, project := range targets {
+			batchArgs = append(batchArgs, runFmt(project))
+		}
+		return tea.Sequence(tea.Batch(batchArgs...), updatesFinished)
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const testFmtDiff = `--- old/main.tf
++++ new/main.tf
+@@ -1,3 +1,3 @@
+ resource "null_resource" "this" {
+-  triggers = { a  = "b" }
++  triggers = { a = "b" }
+ }
+`
+
+// fakeTerraform creates a project whose Terraform binary is a script that
+// prints output and exits with code.
+func fakeTerraform(t *testing.T, script string) string {
+	dir := t.TempDir()
+	binary := filepath.Join(t.TempDir(), "terraform")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	terraformBinaries.set(dir, binary, nil)
+	return dir
+}
+
+func TestCheckFmt(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		formatted string
+		diff      bool
+	}{
+		{"Formatted", "exit 0", ConfigValid, false},
+		{"Needs formatting", "cat <<'EOF'\n" + testFmtDiff + "EOF\nexit 3", ConfigInvalid, true},
+		{"Syntax error", "echo 'Error: Invalid expression' >&2\nexit 2", ConfigUnknown, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			project := Project{Name: "api", Path: fakeTerraform(t, test.script)}
+			result := checkFmt(&project)
+			if project.Formatted != test.formatted {
+				t.Errorf("Expected %q, got %q", test.formatted, project.Formatted)
+			}
+			if (project.FmtDiff != "") != test.diff || result.Diff != project.FmtDiff {
+				t.Errorf("Expected diff %t, got %q", test.diff, project.FmtDiff)
+			}
+			if test.formatted == ConfigUnknown && (result.Err == nil || !strings.Contains(result.Err.Error(), "Invalid expression")) {
+				t.Errorf("Expected the error from Terraform, got %v", result.Err)
+			}
+		})
+	}
+}
+
+func TestRunFmt(t *testing.T) {
+	project := Project{Path: fakeTerraform(t, "echo main.tf"), Formatted: ConfigInvalid, FmtDiff: testFmtDiff}
+	runFmt(&project)()
+	if project.Formatted != ConfigValid || project.FmtDiff != "" {
+		t.Errorf("Expected a formatted project, got %q and %q", project.Formatted, project.FmtDiff)
+	}
+	if project.LastAction != Fmt || !strings.Contains(project.Output, "main.tf") {
+		t.Errorf("Expected the formatted files in the output, got %q", project.Output)
+	}
+}
+
+func TestFmtModel(t *testing.T) {
+	m := createFmtModel([]FmtResult{
+		{Path: "/infra/api", Name: "api", Diff: testFmtDiff},
+		{Path: "/infra/db", Name: "db"},
+	}, 80, 20)
+	if got := m.changed(); !slices.Equal(got, []string{"/infra/api"}) {
+		t.Errorf("Expected only api to need formatting, got %v", got)
+	}
+	if !strings.Contains(m.renderFmt(), "Formatting: 2 projects") {
+		t.Errorf("Expected the header to count the projects, got %q", m.renderFmt())
+	}
+
+	if got := fmtSummary(m.results); got != "fmt: 1 of 2 projects need formatting" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+
+	m.setResult(Project{Path: "/infra/api"})
+	if got := m.changed(); len(got) != 0 {
+		t.Errorf("Expected nothing left to format, got %v", got)
+	}
+}
+
+func TestFmtCheckedKeepsView(t *testing.T) {
+	results := []FmtResult{{Path: "/infra/api", Name: "api", Diff: testFmtDiff}}
+
+	t.Run("Opens the diff from the table", func(t *testing.T) {
+		m := initialModel()
+		m.projects = []Project{{Name: "api", Path: "/infra/api"}}
+		model, _ := m.Update(FmtCheckedMsg{Results: results, Write: true})
+		m = model.(MainModel)
+		if m.state != confirmationView || m.previous != fmtView {
+			t.Errorf("Expected to confirm formatting from the fmt view, got %v and %v", m.state, m.previous)
+		}
+	})
+
+	t.Run("Doesn't take over another view", func(t *testing.T) {
+		m := initialModel()
+		m.confirm("This will apply 1 project", func(m *MainModel) tea.Cmd { return nil })
+		model, _ := m.Update(FmtCheckedMsg{Results: results, Write: true})
+		m = model.(MainModel)
+		if m.state != confirmationView || m.previous != tableView || m.confirmation == nil {
+			t.Errorf("Expected the apply confirmation to stay, got %v and %v", m.state, m.previous)
+		}
+		if !strings.Contains(m.status, "1 of 1 projects need formatting") {
+			t.Errorf("Expected the results in the status, got %q", m.status)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/erikgeiser/promptkit/selection"
+)
+
+const GroupsFileName = "groups.json"
+
+// Groups are named sets of projects. Each entry is a project path, either
+// absolute or relative to the search path, and may be a glob pattern such as
+// "network/*".
+type Groups map[string][]string
+
+// groupsPath returns the file that groups saved from the UI are kept in,
+// next to the config file.
+func groupsPath() string {
+	if ConfigPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(ConfigPath), GroupsFileName)
+}
+
+// loadGroups returns the groups from the config file together with the groups
+// saved at path. A missing file is not an error.
+func loadGroups(path string) (Groups, error) {
+	groups := Groups{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return groups, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &groups); err != nil {
+				return groups, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+
+	for name, patterns := range Settings.Groups {
+		groups[name] = patterns
+	}
+	return groups, nil
+}
+
+// saveGroup adds a group to the file at path, replacing any saved group with
+// the same name. Groups from the config file can't be replaced.
+func saveGroup(path string, name string, paths []string) error {
+	if path == "" {
+		return fmt.Errorf("there is no config directory to save groups in")
+	}
+	if _, ok := Settings.Groups[name]; ok {
+		return fmt.Errorf("group %q is defined in the config file", name)
+	}
+
+	groups := Groups{}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &groups); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	groups[name] = paths
+
+	data, err = json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func matchesGroup(project Project, root string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(root, pattern)
+		}
+		if matched, _ := filepath.Match(pattern, project.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filterGroup returns the projects that belong to a group.
+func filterGroup(projects []Project, root string, patterns []string) []Project {
+	filtered := []Project{}
+	for _, project := range projects {
+		if matchesGroup(project, root, patterns) {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
+
+// resolveGroup looks up a group by name among the configured and saved
+// groups.
+func resolveGroup(name string) ([]string, error) {
+	groups, err := loadGroups(groupsPath())
+	if err != nil {
+		return nil, err
+	}
+	patterns, ok := groups[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown group %q", name)
+	}
+	return patterns, nil
+}
+
+func createGroupPicker(groups Groups) *selection.Model[string] {
+	prompt := selection.New("Select group:", sortedKeys(groups))
+	prompt.Filter = nil
+	prompt.KeyMap.Up = mainKeys.Up.Keys()
+	prompt.KeyMap.Down = mainKeys.Down.Keys()
+	prompt.KeyMap.Select = mainKeys.Submit.Keys()
+	model := selection.NewModel(prompt)
+	model.Init()
+	return model
+}
+
+func createGroupInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = " Save selection as group: "
+	input.Focus()
+	return input
+}
+
+// applyGroup selects the projects of a group, replacing the current
+// selection.
+func (m *MainModel) applyGroup(name string, patterns []string) {
+	paths := []string{}
+	for _, project := range filterGroup(m.projects, SearchPath, patterns) {
+		paths = append(paths, project.Path)
+	}
+	m.table.selectPaths(&m.projects, paths)
+	m.status = fmt.Sprintf("Selected %d projects from %s", len(paths), name)
+}
+
+// saveSelection saves the selected projects as a group. The paths are saved
+// relative to the search path, so the group still works when the repository
+// is checked out somewhere else.
+func (m *MainModel) saveSelection(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+
+	paths := []string{}
+	for _, path := range m.table.visibleSelectedPaths() {
+		paths = append(paths, relativeProjectPath(path))
+	}
+	if err := saveGroup(groupsPath(), name, paths); err != nil {
+		m.status = fmt.Sprintf("Could not save group: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %d projects as %s", len(paths), name)
+}
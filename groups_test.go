@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFilterGroup(t *testing.T) {
+	projects := []Project{
+		{Name: "vpc", Path: "/services/network/vpc"},
+		{Name: "dns", Path: "/services/network/dns"},
+		{Name: "api", Path: "/services/payments/api"},
+		{Name: "db", Path: "/other/db"},
+	}
+
+	cases := map[string]struct {
+		patterns []string
+		want     []string
+	}{
+		"Relative path": {[]string{"payments/api"}, []string{"api"}},
+		"Absolute path": {[]string{"/other/db"}, []string{"db"}},
+		"Glob pattern":  {[]string{"network/*"}, []string{"vpc", "dns"}},
+		"No match":      {[]string{"missing"}, []string{}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			names := []string{}
+			for _, project := range filterGroup(projects, "/services", c.patterns) {
+				names = append(names, project.Name)
+			}
+			if !slices.Equal(names, c.want) {
+				t.Errorf("Expected %v, got %v", c.want, names)
+			}
+		})
+	}
+}
+
+func TestSaveGroup(t *testing.T) {
+	defer func(groups Groups) { Settings.Groups = groups }(Settings.Groups)
+	Settings.Groups = Groups{"prod-network": {"network/*"}}
+	path := filepath.Join(t.TempDir(), "tarragon", GroupsFileName)
+
+	if err := saveGroup(path, "team-payments", []string{"/services/payments/api"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	groups, err := loadGroups(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"prod-network", "team-payments"}; !slices.Equal(sortedKeys(groups), want) {
+		t.Errorf("Expected groups %v, got %v", want, sortedKeys(groups))
+	}
+
+	t.Run("Config groups can't be replaced", func(t *testing.T) {
+		if err := saveGroup(path, "prod-network", []string{"/services/api"}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
+
+func TestSaveSelectionIsRelative(t *testing.T) {
+	defer func(path string, config string) { SearchPath, ConfigPath = path, config }(SearchPath, ConfigPath)
+	ConfigPath = filepath.Join(t.TempDir(), "config.json")
+	SearchPath = "/home/alice/infra"
+
+	m := initialModel()
+	m.projects = []Project{{Name: "api", Path: "/home/alice/infra/services/api"}}
+	m.table.selectPaths(&m.projects, []string{"/home/alice/infra/services/api"})
+	m.saveSelection("payments")
+
+	groups, err := loadGroups(groupsPath())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"services/api"}; !slices.Equal(groups["payments"], want) {
+		t.Errorf("Expected %v, got %v", want, groups["payments"])
+	}
+
+	SearchPath = "/ci/checkout"
+	m.projects = []Project{{Name: "api", Path: "/ci/checkout/services/api"}}
+	m.applyGroup("payments", groups["payments"])
+	if selected := m.table.selectedPaths(); !slices.Equal(selected, []string{"/ci/checkout/services/api"}) {
+		t.Errorf("Expected the group to select the project in the new checkout, got %v", selected)
+	}
+}
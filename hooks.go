@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// hookStages are the points around the main Terraform commands where hooks
+// can run.
+var hookStages = []string{"pre-validate", "post-validate", "pre-plan", "post-plan", "pre-apply", "post-apply"}
+
+// Hook is a command run in the project directory before or after a
+// Terraform command, such as tflint before every plan.
+type Hook struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+
+	// ContinueOnError lets the Terraform command run even if a pre hook
+	// fails. Failing hooks are still shown in the hooks column.
+	ContinueOnError bool `json:"continueOnError"`
+}
+
+func (h Hook) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Command[0]
+}
+
+func validateHooks(hooks map[string][]Hook) error {
+	for _, stage := range sortedKeys(hooks) {
+		if !slices.Contains(hookStages, stage) {
+			return fmt.Errorf("unknown hook %q, must be one of %s", stage, strings.Join(hookStages, ", "))
+		}
+		for _, hook := range hooks[stage] {
+			if len(hook.Command) == 0 {
+				return fmt.Errorf("a %s hook has no command", stage)
+			}
+		}
+	}
+	return nil
+}
+
+// HookError is returned instead of running a Terraform command that a
+// failing pre hook blocks.
+type HookError struct {
+	Stage string
+	Hook  string
+}
+
+func (e HookError) Error() string {
+	return fmt.Sprintf("%s hook %s failed, so %s was not run", e.Stage, e.Hook, strings.TrimPrefix(e.Stage, "pre-"))
+}
+
+// hookRun runs the hooks around one command in a project and records whether
+// they passed in the project.
+type hookRun struct {
+	project *Project
+	command TerraformCommand
+	ran     int
+	failed  int
+}
+
+// startHooks runs the pre hooks of a command. It returns their output and a
+// HookError if one of them failed and blocks the command.
+func startHooks(project *Project, command TerraformCommand) (*hookRun, string, error) {
+	run := &hookRun{project: project, command: command}
+	stage := "pre-" + command.String()
+	output := strings.Builder{}
+	for _, hook := range Settings.Hooks[stage] {
+		out, err := run.runHook(hook)
+		output.WriteString(out)
+		if err != nil && !hook.ContinueOnError {
+			run.record()
+			return run, output.String(), HookError{stage, hook.String()}
+		}
+	}
+	return run, output.String(), nil
+}
+
+// finish runs the post hooks of the command, unless it didn't run or
+// failed, and returns their output.
+func (r *hookRun) finish(err error) string {
+	output := strings.Builder{}
+	if err == nil {
+		for _, hook := range Settings.Hooks["post-"+r.command.String()] {
+			out, _ := r.runHook(hook)
+			output.WriteString(out)
+		}
+	}
+	r.record()
+	return output.String()
+}
+
+func (r *hookRun) record() {
+	switch {
+	case r.ran == 0:
+		r.project.Hooks = ""
+	case r.failed > 0:
+		r.project.Hooks = ConfigInvalid
+	default:
+		r.project.Hooks = ConfigValid
+	}
+}
+
+// runHook runs a hook in the project directory with the project's
+// environment and returns its output, preceded by the command line.
+func (r *hookRun) runHook(hook Hook) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if timeout := Settings.timeout("hook"); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	out := bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, projectProgram(*r.project, hook.Command[0]), hook.Command[1:]...)
+	cmd.Dir = r.project.Path
+	cmd.Env = slices.Concat(os.Environ(), projectEnv(*r.project), []string{"TARRAGON_COMMAND=" + r.command.String()})
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	r.ran++
+	output := fmt.Sprintf("$ %s\n%s", strings.Join(hook.Command, " "), out.String())
+	if err != nil {
+		r.failed++
+		output += fmt.Sprintf("%s: %s\n", hook, err)
+	}
+	return maskedOutput(r.project.Path, output) + "\n", err
+}
+
+func formatHooks(status string) string {
+	if status == "" {
+		return tableDate.Render("-")
+	}
+	return formatValid(status)
+}
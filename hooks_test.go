@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHooks(t *testing.T) {
+	if err := validateHooks(map[string][]Hook{"pre-plan": {{Command: []string{"tflint"}}}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateHooks(map[string][]Hook{"before-plan": {{Command: []string{"tflint"}}}}); err == nil {
+		t.Error("Expected an error for an unknown stage")
+	}
+	if err := validateHooks(map[string][]Hook{"pre-plan": {{Name: "lint"}}}); err == nil {
+		t.Error("Expected an error for a hook without a command")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	defer func(hooks map[string][]Hook) { Settings.Hooks = hooks }(Settings.Hooks)
+
+	t.Run("Failing pre hook blocks the command", func(t *testing.T) {
+		Settings.Hooks = map[string][]Hook{
+			"pre-plan": {
+				{Name: "lint", Command: []string{"sh", "-c", "echo 2 issues; exit 1"}},
+				{Name: "cost", Command: []string{"echo", "never runs"}},
+			},
+		}
+		project := Project{Name: "api", Path: t.TempDir()}
+		runPlan(&project)()
+
+		if project.PlanChanges.Add != PlanError.Value() || project.Hooks != ConfigInvalid {
+			t.Errorf("Expected a blocked plan with failed hooks, got %v and %q", project.PlanChanges, project.Hooks)
+		}
+		if !strings.Contains(project.Output, "2 issues") || !strings.Contains(project.Output, "plan was not run") {
+			t.Errorf("Expected the hook output and the reason, got %q", project.Output)
+		}
+		if strings.Contains(project.Output, "never runs") {
+			t.Errorf("Expected the hooks after the failing one to be skipped, got %q", project.Output)
+		}
+	})
+
+	t.Run("Failing command skips post hooks", func(t *testing.T) {
+		Settings.Hooks = map[string][]Hook{
+			"post-plan":  {{Command: []string{"echo", "after plan"}}},
+			"post-apply": {{Command: []string{"echo", "after apply"}}},
+		}
+		project := Project{Path: fakeTerraform(t, "echo 'Error: Invalid provider configuration'\nexit 1")}
+
+		runPlan(&project)()
+		if strings.Contains(project.Output, "after plan") {
+			t.Errorf("Expected the post-plan hook not to run, got %q", project.Output)
+		}
+		runApply(&project)()
+		if strings.Contains(project.Output, "after apply") {
+			t.Errorf("Expected the post-apply hook not to run, got %q", project.Output)
+		}
+	})
+
+	t.Run("Continue on error", func(t *testing.T) {
+		Settings.Hooks = map[string][]Hook{
+			"pre-validate":  {{Command: []string{"false"}, ContinueOnError: true}},
+			"post-validate": {{Command: []string{"echo", "after"}}},
+		}
+		project := Project{Path: t.TempDir()}
+		hooks, _, err := startHooks(&project, Validate)
+		if err != nil {
+			t.Fatalf("Expected the command not to be blocked, got %v", err)
+		}
+		if output := hooks.finish(nil); !strings.Contains(output, "$ echo after") {
+			t.Errorf("Expected the post hook to run, got %q", output)
+		}
+		if project.Hooks != ConfigInvalid {
+			t.Errorf("Expected the failed hook to be recorded, got %q", project.Hooks)
+		}
+	})
+
+	t.Run("Hooks use the project's Terraform", func(t *testing.T) {
+		Settings.Hooks = map[string][]Hook{"pre-plan": {{Command: []string{"terraform", "fmt", "-check"}}}}
+		project := Project{Path: fakeTerraform(t, "echo project terraform $@")}
+		_, output, _ := startHooks(&project, Plan)
+		if !strings.Contains(output, "project terraform fmt -check") {
+			t.Errorf("Expected the project's Terraform binary to run, got %q", output)
+		}
+	})
+
+	t.Run("Passing hooks", func(t *testing.T) {
+		Settings.Hooks = map[string][]Hook{"pre-apply": {{Command: []string{"true"}}}}
+		project := Project{Path: t.TempDir()}
+		hooks, _, err := startHooks(&project, Apply)
+		hooks.finish(err)
+		if err != nil || project.Hooks != ConfigValid {
+			t.Errorf("Expected the hooks to pass, got %v and %q", err, project.Hooks)
+		}
+	})
+}
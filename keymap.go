@@ -1,7 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/evertras/bubble-table/table"
 )
 
 type KeyMap struct {
@@ -11,6 +19,12 @@ type KeyMap struct {
 	Quit                key.Binding
 	Up                  key.Binding
 	Down                key.Binding
+	PageUp              key.Binding
+	PageDown            key.Binding
+	PageFirst           key.Binding
+	PageLast            key.Binding
+	ScrollLeft          key.Binding
+	ScrollRight         key.Binding
 	Yes                 key.Binding
 	No                  key.Binding
 	Filter              key.Binding
@@ -24,6 +38,50 @@ type KeyMap struct {
 	ValidateSelected    key.Binding
 	ApplyHighlighted    key.Binding
 	ApplySelected       key.Binding
+	InspectState        key.Binding
+	Open                key.Binding
+	Submit              key.Binding
+	StateMove           key.Binding
+	StateRemove         key.Binding
+	Import              key.Binding
+	Taint               key.Binding
+	Untaint             key.Binding
+	ShowOutputs         key.Binding
+	Reveal              key.Binding
+	Copy                key.Binding
+	ForceUnlock         key.Binding
+	SortColumn          key.Binding
+	SortOrder           key.Binding
+	ToggleTree          key.Binding
+	Expand              key.Binding
+	Collapse            key.Binding
+	ExportReport        key.Binding
+	ShowDiagnostics     key.Binding
+	EditFile            key.Binding
+	OpenEditor          key.Binding
+	OpenShell           key.Binding
+	NextMatch           key.Binding
+	PrevMatch           key.Binding
+	NextError           key.Binding
+	PrevError           key.Binding
+	NextChange          key.Binding
+	PrevChange          key.Binding
+	ToggleSplit         key.Binding
+	GrowSplit           key.Binding
+	ShrinkSplit         key.Binding
+	ApplyGroup          key.Binding
+	SaveGroup           key.Binding
+	ShowProviders       key.Binding
+	ToggleMatrix        key.Binding
+	LockProviders       key.Binding
+	UpgradeProviders    key.Binding
+	ChooseProfile       key.Binding
+	ShowFmtDiff         key.Binding
+	FormatProjects      key.Binding
+
+	// Commands are the bindings of the custom commands from the config file,
+	// which are active in the table and tree.
+	Commands []key.Binding
 }
 
 var mainKeys = KeyMap{
@@ -51,12 +109,36 @@ var mainKeys = KeyMap{
 		key.WithKeys("j", "down"),
 		key.WithHelp("j/↓", "down"),
 	),
+	PageUp: key.NewBinding(
+		key.WithKeys("h", "left", "pgup"),
+		key.WithHelp("h/←", "prev page"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("l", "right", "pgdown"),
+		key.WithHelp("l/→", "next page"),
+	),
+	PageFirst: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "first page"),
+	),
+	PageLast: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "last page"),
+	),
+	ScrollLeft: key.NewBinding(
+		key.WithKeys("shift+left"),
+		key.WithHelp("shift+←", "scroll left"),
+	),
+	ScrollRight: key.NewBinding(
+		key.WithKeys("shift+right"),
+		key.WithHelp("shift+→", "scroll right"),
+	),
 	Yes: key.NewBinding(
-		key.WithKeys("y"),
+		key.WithKeys("y", "Y"),
 		key.WithHelp("y", "yes"),
 	),
 	No: key.NewBinding(
-		key.WithKeys("n"),
+		key.WithKeys("n", "N"),
 		key.WithHelp("n", "no"),
 	),
 	Filter: key.NewBinding(
@@ -64,7 +146,7 @@ var mainKeys = KeyMap{
 		key.WithHelp("/", "filter"),
 	),
 	Select: key.NewBinding(
-		key.WithKeys("space", "enter"),
+		key.WithKeys(" ", "enter"),
 		key.WithHelp("space", "select"),
 	),
 	SelectAll: key.NewBinding(
@@ -103,6 +185,166 @@ var mainKeys = KeyMap{
 		key.WithKeys("A"),
 		key.WithHelp("A", "apply: selected"),
 	),
+	InspectState: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "inspect state"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("enter", "open/expand"),
+	),
+	Submit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "submit"),
+	),
+	StateMove: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "state mv"),
+	),
+	StateRemove: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "state rm"),
+	),
+	Import: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "import"),
+	),
+	Taint: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "taint"),
+	),
+	Untaint: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "untaint"),
+	),
+	ShowOutputs: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "outputs"),
+	),
+	Reveal: key.NewBinding(
+		key.WithKeys("r", "enter"),
+		key.WithHelp("r", "reveal sensitive"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy value"),
+	),
+	ForceUnlock: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "force-unlock"),
+	),
+	SortColumn: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "sort column"),
+	),
+	SortOrder: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "sort direction"),
+	),
+	ToggleTree: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "toggle tree"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("l", "right"),
+		key.WithHelp("l/→", "expand"),
+	),
+	Collapse: key.NewBinding(
+		key.WithKeys("h", "left"),
+		key.WithHelp("h/←", "collapse"),
+	),
+	ExportReport: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "export report"),
+	),
+	ShowDiagnostics: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "diagnostics"),
+	),
+	EditFile: key.NewBinding(
+		key.WithKeys("e", "enter"),
+		key.WithHelp("e", "open in editor"),
+	),
+	OpenEditor: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "open editor"),
+	),
+	OpenShell: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "open shell"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "prev match"),
+	),
+	NextError: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "next error"),
+	),
+	PrevError: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "prev error"),
+	),
+	NextChange: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "next change"),
+	),
+	PrevChange: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "prev change"),
+	),
+	ToggleSplit: key.NewBinding(
+		key.WithKeys("|"),
+		key.WithHelp("|", "toggle split"),
+	),
+	GrowSplit: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "grow projects"),
+	),
+	ShrinkSplit: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "shrink projects"),
+	),
+	ApplyGroup: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "select group"),
+	),
+	SaveGroup: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "save group"),
+	),
+	ShowProviders: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "providers"),
+	),
+	ToggleMatrix: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "all projects"),
+	),
+	LockProviders: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "providers lock"),
+	),
+	UpgradeProviders: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "init -upgrade"),
+	),
+	ChooseProfile: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "env profile"),
+	),
+	ShowFmtDiff: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "fmt diff"),
+	),
+	FormatProjects: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "fmt"),
+	),
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -113,8 +355,230 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.ValidateHighlighted, k.PlanHighlighted, k.ApplyHighlighted},
 		{k.ValidateSelected, k.PlanSelected, k.ApplySelected},
-		{k.Select, k.SelectAll, k.DeselectAll},
-		{k.ToggleOutput, k.Refresh, k.Filter},
+		{k.Select, k.SelectAll, k.DeselectAll, k.ApplyGroup, k.SaveGroup, k.ChooseProfile, k.FormatProjects},
+		{k.PageUp, k.PageDown, k.PageFirst, k.PageLast},
+		{k.ToggleOutput, k.ToggleTree, k.ShowDiagnostics, k.ShowFmtDiff, k.InspectState, k.ShowOutputs, k.ShowProviders, k.ForceUnlock},
+		{k.ToggleSplit, k.GrowSplit, k.ShrinkSplit},
+		{k.OpenEditor, k.OpenShell, k.ExportReport},
+		{k.Refresh, k.Filter, k.SortColumn, k.SortOrder},
+		k.Commands,
 		{k.Help, k.Quit},
 	}
 }
+
+// viewHelp lists the bindings shown in the help bar of a secondary view.
+type viewHelp []key.Binding
+
+func (v viewHelp) ShortHelp() []key.Binding {
+	return v
+}
+
+func (v viewHelp) FullHelp() [][]key.Binding {
+	return [][]key.Binding{v}
+}
+
+func (k KeyMap) StateHelp(showingResource bool) viewHelp {
+	if showingResource {
+		return viewHelp{k.Up, k.Down, k.Open, k.Cancel}
+	}
+	return viewHelp{k.Up, k.Down, k.Open, k.Filter, k.StateMove, k.StateRemove, k.Import, k.Taint, k.Untaint, k.Cancel}
+}
+
+func (k KeyMap) TreeHelp() viewHelp {
+	return viewHelp{
+		k.Up, k.Down, k.Expand, k.Collapse, k.Select, k.ValidateSelected, k.PlanSelected,
+		k.ApplySelected, k.ApplyGroup, k.ChooseProfile, k.FormatProjects, k.Filter, k.ToggleTree, k.ToggleSplit, k.ExportReport, k.Help,
+		k.Quit,
+	}
+}
+
+func (k KeyMap) DiagnosticsHelp() viewHelp {
+	return viewHelp{k.Up, k.Down, k.EditFile, k.Cancel}
+}
+
+func (k KeyMap) PickerHelp() viewHelp {
+	return viewHelp{k.Up, k.Down, k.Submit, k.Cancel}
+}
+
+func (k KeyMap) PromptHelp() viewHelp {
+	return viewHelp{k.Submit, k.Cancel}
+}
+
+func (k KeyMap) OutputsHelp() viewHelp {
+	return viewHelp{k.Up, k.Down, k.Reveal, k.Copy, k.Cancel}
+}
+
+func (k KeyMap) FmtHelp() viewHelp {
+	return viewHelp{k.Up, k.Down, k.FormatProjects, k.Cancel}
+}
+
+func (k KeyMap) ProvidersHelp() viewHelp {
+	return viewHelp{k.Up, k.Down, k.ToggleMatrix, k.LockProviders, k.UpgradeProviders, k.Cancel}
+}
+
+// keyContexts lists the bindings that are active at the same time in each
+// view. A key may only be bound to one action per context.
+var keyContexts = map[string][]string{
+	"table": {
+		"ToggleOutput", "Help", "Quit", "Up", "Down", "PageUp", "PageDown", "PageFirst", "PageLast",
+		"ScrollLeft", "ScrollRight", "Filter", "Refresh", "Select", "SelectAll", "DeselectAll",
+		"PlanHighlighted", "PlanSelected", "ValidateHighlighted", "ValidateSelected",
+		"ApplyHighlighted", "ApplySelected", "InspectState", "ShowOutputs", "ForceUnlock",
+		"SortColumn", "SortOrder", "ToggleTree", "ExportReport", "ShowDiagnostics", "OpenEditor",
+		"OpenShell", "ToggleSplit", "GrowSplit", "ShrinkSplit", "ApplyGroup", "SaveGroup",
+		"ShowProviders", "ChooseProfile", "ShowFmtDiff", "FormatProjects",
+	},
+	"tree": {
+		"ToggleOutput", "Help", "Quit", "Up", "Down", "Expand", "Collapse", "Filter", "Refresh",
+		"Select", "SelectAll", "DeselectAll", "PlanHighlighted", "PlanSelected",
+		"ValidateHighlighted", "ValidateSelected", "ApplyHighlighted", "ApplySelected",
+		"InspectState", "ShowOutputs", "ForceUnlock", "ToggleTree", "ExportReport", "ShowDiagnostics",
+		"OpenEditor", "OpenShell", "ToggleSplit", "GrowSplit", "ShrinkSplit", "ApplyGroup",
+		"SaveGroup", "ShowProviders", "ChooseProfile", "ShowFmtDiff", "FormatProjects",
+	},
+	"picker":      {"Up", "Down", "Submit", "Cancel"},
+	"diagnostics": {"Up", "Down", "EditFile", "Cancel"},
+	"fmt":         {"Up", "Down", "PageUp", "PageDown", "FormatProjects", "Cancel"},
+	"output": {
+		"Up", "Down", "PageUp", "PageDown", "ToggleOutput", "Filter", "Cancel", "NextMatch", "PrevMatch", "NextError", "PrevError",
+		"NextChange", "PrevChange",
+	},
+	"confirmation": {"Yes", "No", "Cancel"},
+	"state": {
+		"Up", "Down", "PageUp", "PageDown", "PageFirst", "PageLast", "Filter", "Open", "Cancel",
+		"StateMove", "StateRemove", "Import", "Taint", "Untaint",
+	},
+	"prompt":  {"Submit", "Cancel"},
+	"outputs": {"Up", "Down", "Reveal", "Copy", "Cancel"},
+	"providers": {
+		"Up", "Down", "ToggleMatrix", "LockProviders", "UpgradeProviders", "Cancel",
+	},
+}
+
+// bindingName converts a KeyMap field name into the name used in the config
+// file, e.g. ApplySelected -> applySelected.
+func bindingName(field string) string {
+	runes := []rune(field)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// normalizeKey maps friendly key names from the config file to the names
+// reported by Bubble Tea.
+func normalizeKey(k string) string {
+	if k == "space" {
+		return " "
+	}
+	return k
+}
+
+func helpKey(keys []string) string {
+	labels := []string{}
+	for _, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		labels = append(labels, k)
+	}
+	return strings.Join(labels, "/")
+}
+
+func (k *KeyMap) binding(name string) (*key.Binding, bool) {
+	field := reflect.ValueOf(k).Elem().FieldByNameFunc(func(field string) bool {
+		return bindingName(field) == name
+	})
+	if !field.IsValid() || field.Type() != reflect.TypeOf(key.Binding{}) {
+		return nil, false
+	}
+	return field.Addr().Interface().(*key.Binding), true
+}
+
+// withOverrides returns a copy of the key map with bindings replaced by the
+// keys from the config file. The help text is updated to show the new keys.
+func (k KeyMap) withOverrides(overrides map[string][]string) (KeyMap, error) {
+	for _, name := range sortedKeys(overrides) {
+		binding, ok := k.binding(name)
+		if !ok {
+			return k, fmt.Errorf("unknown key binding %q", name)
+		}
+
+		keys := []string{}
+		for _, key := range overrides[name] {
+			keys = append(keys, normalizeKey(key))
+		}
+		if len(keys) == 0 {
+			return k, fmt.Errorf("key binding %q has no keys", name)
+		}
+
+		binding.SetKeys(keys...)
+		binding.SetHelp(helpKey(keys), binding.Help().Desc)
+	}
+	return k, nil
+}
+
+// conflicts reports keys that are bound to more than one action in the same
+// view.
+func (k KeyMap) conflicts() []string {
+	conflicts := []string{}
+	for _, context := range sortedKeys(keyContexts) {
+		owners := map[string]string{}
+		for _, field := range keyContexts[context] {
+			binding, _ := k.binding(bindingName(field))
+			for _, key := range binding.Keys() {
+				if owner, taken := owners[key]; taken {
+					conflicts = append(conflicts, fmt.Sprintf(
+						"key %q is bound to both %s and %s in the %s view",
+						helpKey([]string{key}), owner, bindingName(field), context,
+					))
+					continue
+				}
+				owners[key] = bindingName(field)
+			}
+		}
+		if context != "table" && context != "tree" {
+			continue
+		}
+		for _, binding := range k.Commands {
+			for _, key := range binding.Keys() {
+				if owner, taken := owners[key]; taken {
+					conflicts = append(conflicts, fmt.Sprintf(
+						"key %q is bound to both %s and the %s command in the %s view",
+						helpKey([]string{key}), owner, binding.Help().Desc, context,
+					))
+					continue
+				}
+				owners[key] = binding.Help().Desc
+			}
+		}
+	}
+	return conflicts
+}
+
+// tableKeyMap converts the key map into the bindings used by bubble-table.
+func (k KeyMap) tableKeyMap() table.KeyMap {
+	tableKeys := table.DefaultKeyMap()
+	tableKeys.RowDown.SetKeys(k.Down.Keys()...)
+	tableKeys.RowUp.SetKeys(k.Up.Keys()...)
+	tableKeys.RowSelectToggle.SetKeys(k.Select.Keys()...)
+	tableKeys.PageDown.SetKeys(k.PageDown.Keys()...)
+	tableKeys.PageUp.SetKeys(k.PageUp.Keys()...)
+	tableKeys.PageFirst.SetKeys(k.PageFirst.Keys()...)
+	tableKeys.PageLast.SetKeys(k.PageLast.Keys()...)
+	tableKeys.ScrollLeft.SetKeys(k.ScrollLeft.Keys()...)
+	tableKeys.ScrollRight.SetKeys(k.ScrollRight.Keys()...)
+	tableKeys.Filter.SetKeys(k.Filter.Keys()...)
+	tableKeys.FilterClear.SetKeys(k.Cancel.Keys()...)
+	tableKeys.FilterBlur.SetKeys(slices.Concat(k.Submit.Keys(), k.Cancel.Keys())...)
+	return tableKeys
+}
+
+// viewportKeyMap converts the key map into the bindings used to scroll the
+// output and fmt views.
+func (k KeyMap) viewportKeyMap() viewport.KeyMap {
+	viewportKeys := viewport.DefaultKeyMap()
+	viewportKeys.Up.SetKeys(k.Up.Keys()...)
+	viewportKeys.Down.SetKeys(k.Down.Keys()...)
+	viewportKeys.PageUp.SetKeys(k.PageUp.Keys()...)
+	viewportKeys.PageDown.SetKeys(k.PageDown.Keys()...)
+	return viewportKeys
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDefaultKeysHaveNoConflicts(t *testing.T) {
+	if conflicts := mainKeys.conflicts(); len(conflicts) > 0 {
+		t.Errorf("Unexpected conflicts: %v", conflicts)
+	}
+}
+
+func TestKeyContextsAreValid(t *testing.T) {
+	for context, fields := range keyContexts {
+		for _, field := range fields {
+			if _, ok := mainKeys.binding(bindingName(field)); !ok {
+				t.Errorf("Unknown binding %s in %s context", field, context)
+			}
+		}
+	}
+}
+
+func TestKeyOverrides(t *testing.T) {
+	t.Run("Replaces keys and help", func(t *testing.T) {
+		keys, err := mainKeys.withOverrides(map[string][]string{"applyHighlighted": {"ctrl+a", "space"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(keys.ApplyHighlighted.Keys(), []string{"ctrl+a", " "}) {
+			t.Errorf("Unexpected keys: %v", keys.ApplyHighlighted.Keys())
+		}
+		if keys.ApplyHighlighted.Help().Key != "ctrl+a/space" {
+			t.Errorf("Unexpected help key: %s", keys.ApplyHighlighted.Help().Key)
+		}
+		if slices.Equal(mainKeys.ApplyHighlighted.Keys(), keys.ApplyHighlighted.Keys()) {
+			t.Error("Expected the original key map to be unchanged")
+		}
+	})
+
+	t.Run("Unknown binding", func(t *testing.T) {
+		if _, err := mainKeys.withOverrides(map[string][]string{"launchRockets": {"x"}}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("Detects conflicts", func(t *testing.T) {
+		keys, err := mainKeys.withOverrides(map[string][]string{"applySelected": {"p"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		conflicts := keys.conflicts()
+		if len(conflicts) == 0 {
+			t.Fatal("Expected a conflict for applySelected")
+		}
+		for _, conflict := range conflicts {
+			if !strings.Contains(conflict, "applySelected") {
+				t.Errorf("Expected only conflicts for applySelected, got %v", conflicts)
+			}
+		}
+	})
+}
+
+func TestConfirmationKeys(t *testing.T) {
+	t.Run("Upper case confirms", func(t *testing.T) {
+		ran := false
+		m := initialModel()
+		m.confirm(applyWarning, func(m *MainModel) tea.Cmd { ran = true; return nil })
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+		m = model.(MainModel)
+		if !ran || m.state != tableView {
+			t.Errorf("Expected the task to run, got %v and %v", ran, m.state)
+		}
+	})
+
+	t.Run("Only the bound keys confirm", func(t *testing.T) {
+		defer func(keys KeyMap) { mainKeys = keys }(mainKeys)
+		keys, err := mainKeys.withOverrides(map[string][]string{"yes": {"ctrl+y"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		mainKeys = keys
+
+		prompt := createConfirmation(applyWarning)
+		if !slices.Equal(prompt.KeyMap.Yes, []string{"ctrl+y"}) || !slices.Equal(prompt.KeyMap.SelectYes, []string{"ctrl+y"}) {
+			t.Errorf("Expected only ctrl+y to confirm, got %v and %v", prompt.KeyMap.Yes, prompt.KeyMap.SelectYes)
+		}
+	})
+}
+
+func TestViewportKeys(t *testing.T) {
+	defer func(keys KeyMap) { mainKeys = keys }(mainKeys)
+	keys, err := mainKeys.withOverrides(map[string][]string{"down": {"ctrl+n"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mainKeys = keys
+
+	m := createFmtModel([]FmtResult{{Name: "api", Diff: strings.Repeat("+line\n", 50)}}, 80, 20)
+	m.viewport, _ = m.viewport.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("Expected j to be unbound, got offset %d", m.viewport.YOffset)
+	}
+	m.viewport, _ = m.viewport.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m.viewport.YOffset != 1 {
+		t.Errorf("Expected ctrl+n to scroll down, got offset %d", m.viewport.YOffset)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SplitMode is how the projects share the screen with the highlighted
+// project's output.
+type SplitMode string
+
+const (
+	SplitNone       SplitMode = "none"
+	SplitHorizontal SplitMode = "horizontal"
+	SplitVertical   SplitMode = "vertical"
+)
+
+var splitModes = []SplitMode{SplitNone, SplitHorizontal, SplitVertical}
+
+const (
+	defaultSplitSize = 50
+	minSplitSize     = 20
+	maxSplitSize     = 80
+	splitStep        = 5
+
+	// chromeHeight is the number of lines below the projects that are taken
+	// up by the progress bar and the help.
+	chromeHeight = 3
+
+	// paneOffset is the number of lines above the projects table or tree
+	// (the spacing and filter) and below it (the spacing).
+	paneOffset = 3
+	paneMargin = 2
+)
+
+// Layout holds the size of the projects table or tree and of the output pane
+// shown next to it. The pane is empty when the split is off.
+type Layout struct {
+	ProjectsWidth  int
+	ProjectsHeight int
+	PaneWidth      int
+	PaneHeight     int
+}
+
+func validateSplit(split SplitMode, size int) error {
+	if !slices.Contains(splitModes, split) {
+		return fmt.Errorf("unknown split %q, must be one of none, horizontal or vertical", split)
+	}
+	if size < minSplitSize || size > maxSplitSize {
+		return fmt.Errorf("splitSize must be between %d and %d, got %d", minSplitSize, maxSplitSize, size)
+	}
+	return nil
+}
+
+// nextSplit cycles through the split modes: off, side by side and stacked.
+func nextSplit(split SplitMode) SplitMode {
+	i := slices.Index(splitModes, split)
+	return splitModes[(i+1)%len(splitModes)]
+}
+
+// computeLayout divides the window between the projects and the output pane.
+// size is the share of the window, in percent, given to the projects.
+func computeLayout(width int, height int, split SplitMode, size int) Layout {
+	available := max(height-chromeHeight, 1)
+	switch split {
+	case SplitHorizontal:
+		projectsWidth := width * size / 100
+		return Layout{
+			ProjectsWidth:  projectsWidth,
+			ProjectsHeight: available,
+			PaneWidth:      max(width-projectsWidth-1, 1),
+			PaneHeight:     max(available-paneOffset-paneMargin, 1),
+		}
+	case SplitVertical:
+		projectsHeight := available * size / 100
+		return Layout{
+			ProjectsWidth:  width,
+			ProjectsHeight: projectsHeight,
+			PaneWidth:      width,
+			PaneHeight:     max(available-projectsHeight, 1),
+		}
+	default:
+		return Layout{ProjectsWidth: width, ProjectsHeight: available}
+	}
+}
+
+func (m MainModel) layout() Layout {
+	return computeLayout(WinSize.Width, WinSize.Height, m.split, m.splitSize)
+}
+
+// resize fits every view to the current window size.
+func (m *MainModel) resize() {
+	layout := m.layout()
+	m.table.resize(layout.ProjectsWidth, layout.ProjectsHeight)
+	m.output.setSize(WinSize.Width, WinSize.Height)
+	m.pane.setSize(layout.PaneWidth, layout.PaneHeight)
+	m.stateBrowser.setSize(WinSize.Width, WinSize.Height)
+	m.outputsPanel.setSize(WinSize.Width, WinSize.Height)
+	m.providers.setSize(WinSize.Width, WinSize.Height)
+	m.fmt.setSize(WinSize.Width, WinSize.Height)
+	m.diagnostics.setSize(WinSize.Width, WinSize.Height)
+	m.progress.Width = WinSize.Width
+	m.help.Width = WinSize.Width
+}
+
+func (m *MainModel) toggleSplit() {
+	m.split = nextSplit(m.split)
+	m.panePath = ""
+	m.resize()
+	m.updatePane()
+}
+
+func (m *MainModel) resizeSplit(delta int) {
+	if m.split == SplitNone {
+		return
+	}
+	m.splitSize = max(minSplitSize, min(m.splitSize+delta, maxSplitSize))
+	m.resize()
+}
+
+// updatePane shows the highlighted project's output in the pane. The content
+// is only replaced when the project or its output changed, so the scroll
+// position is kept while nothing happens.
+func (m *MainModel) updatePane() {
+	if m.split == SplitNone {
+		return
+	}
+
+	project := m.highlightedRow()
+	if project.Path == m.panePath && project.Output == m.pane.content && project.LastAction == m.pane.action {
+		return
+	}
+	m.panePath = project.Path
+	m.pane.setTitle(project.Name, project.LastAction)
+	m.pane.setContent(project.Output)
+}
+
+// renderSplit places the output pane beside or below the rendered projects.
+func (m MainModel) renderSplit(projects string) string {
+	switch m.split {
+	case SplitHorizontal:
+		pane := strings.Repeat("\n", paneOffset) + m.pane.renderOutput()
+		return lipgloss.JoinHorizontal(lipgloss.Top, strings.TrimSuffix(projects, "\n"), " ", pane) + "\n"
+	case SplitVertical:
+		return projects + m.pane.renderOutput() + "\n"
+	default:
+		return projects
+	}
+}
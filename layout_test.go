@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestComputeLayout(t *testing.T) {
+	t.Run("No split", func(t *testing.T) {
+		got := computeLayout(120, 40, SplitNone, 50)
+		want := Layout{ProjectsWidth: 120, ProjectsHeight: 37}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Side by side", func(t *testing.T) {
+		got := computeLayout(120, 40, SplitHorizontal, 40)
+		want := Layout{ProjectsWidth: 48, ProjectsHeight: 37, PaneWidth: 71, PaneHeight: 32}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Stacked", func(t *testing.T) {
+		got := computeLayout(120, 40, SplitVertical, 60)
+		want := Layout{ProjectsWidth: 120, ProjectsHeight: 22, PaneWidth: 120, PaneHeight: 15}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+}
+
+func TestViewFitsWindow(t *testing.T) {
+	WinSize.Width, WinSize.Height = 100, 30
+	projects := []Project{}
+	for i := range 50 {
+		projects = append(projects, Project{Name: fmt.Sprintf("project%02d", i), Path: fmt.Sprintf("/services/project%02d", i)})
+	}
+
+	for _, split := range splitModes {
+		for _, showTree := range []bool{false, true} {
+			m := initialModel()
+			m.split, m.showTree = split, showTree
+			m.projects = projects
+			m.table.updateData(&m.projects)
+			m.resize()
+
+			if height := lipgloss.Height(m.View()); height > WinSize.Height {
+				t.Errorf("Expected the %s split (tree: %t) to fit in %d lines, got %d", split, showTree, WinSize.Height, height)
+			}
+		}
+	}
+}
+
+func TestWindowResize(t *testing.T) {
+	WinSize.Width, WinSize.Height = 120, 40
+	m := initialModel()
+
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	m = model.(MainModel)
+
+	if WinSize.Width != 60 || WinSize.Height != 20 {
+		t.Errorf("Expected the window size to be updated, got %+v", WinSize)
+	}
+	if !m.table.compact {
+		t.Error("Expected the table to be compact")
+	}
+	if m.progress.Width != 60 || m.help.Width != 60 || m.output.viewport.Width != 60 {
+		t.Errorf("Expected the progress, help and output to be 60 wide, got %d, %d and %d", m.progress.Width, m.help.Width, m.output.viewport.Width)
+	}
+	if height := lipgloss.Height(m.View()); height > 20 {
+		t.Errorf("Expected the view to fit in 20 lines, got %d", height)
+	}
+}
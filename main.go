@@ -12,9 +12,11 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/erikgeiser/promptkit/confirmation"
+	"github.com/erikgeiser/promptkit/selection"
 	tsize "github.com/kopoli/go-terminal-size"
 )
 
@@ -23,7 +25,13 @@ var (
 	version           string
 	WinSize           tsize.Size
 	SearchPath        string
+	ConfigPath        string
+	ExportPath        string
+	ExportFormat      string
+	ExportPlan        bool
+	GroupName         string
 	Debug             bool
+	Mouse             bool
 	ValidateOnRefresh bool = true
 )
 
@@ -33,34 +41,79 @@ const (
 	tableView State = iota
 	outputView
 	confirmationView
+	stateView
+	outputsView
+	exportView
+	diagnosticsView
+	groupView
+	saveGroupView
+	providersView
+	fmtView
+	profileView
 )
 
 type MainModel struct {
-	err          error
-	confirmation *confirmation.Model
-	task         func(*MainModel) tea.Cmd
-	help         help.Model
-	message      string
-	keys         KeyMap
-	projects     []Project
-	output       OutputModel
-	spinner      spinner.Model
-	table        TableModel
-	progress     progress.Model
-	percent      float64
-	state        State
-	working      bool
-	refreshing   bool
+	err           error
+	confirmation  *confirmation.Model
+	formatPicker  *selection.Model[ReportFormat]
+	groupPicker   *selection.Model[string]
+	profilePicker *selection.Model[string]
+	groupInput    textinput.Model
+	groups        Groups
+	group         string
+	task          func(*MainModel) tea.Cmd
+	help          help.Model
+	message       string
+	status        string
+	keys          KeyMap
+	projects      []Project
+	output        OutputModel
+	stateBrowser  StateModel
+	outputsPanel  OutputsModel
+	providers     ProvidersModel
+	fmt           FmtModel
+	diagnostics   DiagnosticsModel
+	spinner       spinner.Model
+	table         TableModel
+	tree          TreeModel
+	pane          OutputModel
+	panePath      string
+	split         SplitMode
+	splitSize     int
+	progress      progress.Model
+	percent       float64
+	state         State
+	previous      State
+	working       bool
+	refreshing    bool
+	showTree      bool
 }
 
 type Project struct {
-	LastModified time.Time
-	Name         string
-	Path         string
-	LastAction   TerraformCommand
-	Output       string
-	Valid        string
-	PlanChanges  TerraformChanges
+	LastModified     time.Time
+	LastRun          time.Time
+	LastDuration     time.Duration
+	Name             string
+	Path             string
+	Workspace        string
+	Backend          string
+	TerraformVersion string
+	RequiredVersion  string
+	PinnedVersion    string
+	ResolvedVersion  string
+	VersionMismatch  error
+	Profile          string
+	Hooks            string
+	Command          string
+	CommandStatus    string
+	Formatted        string
+	FmtDiff          string
+	LastAction       TerraformCommand
+	Output           string
+	Valid            string
+	PlanChanges      TerraformChanges
+	Lock             LockInfo
+	Diagnostics      []Diagnostic
 }
 
 type (
@@ -82,25 +135,42 @@ func initialModel() MainModel {
 	table := createProjectsTable()
 	output := OutputModel{width: WinSize.Width, height: WinSize.Height}
 	output.createViewport()
+	pane := OutputModel{}
+	pane.createViewport()
 
 	main := MainModel{
 		state:        tableView,
 		table:        table,
+		tree:         createTreeModel(),
 		output:       output,
-		confirmation: createConfirmation(),
+		pane:         pane,
+		split:        Settings.Split,
+		splitSize:    Settings.SplitSize,
+		group:        GroupName,
+		confirmation: createConfirmation(applyWarning),
 		keys:         mainKeys,
 		help:         help.New(),
 		spinner:      s,
-		progress: progress.New(
-			progress.WithGradient("#737c73", "#8992a7"),
-			progress.WithWidth(WinSize.Width),
-		),
-		working:    false,
-		refreshing: false,
+		progress:     createProgress(),
+		working:      false,
+		refreshing:   false,
 	}
+	main.resize()
 	return main
 }
 
+func createProgress() progress.Model {
+	if currentTheme.ProgressStart == "" {
+		bar := progress.New(progress.WithSolidFill(""), progress.WithWidth(WinSize.Width))
+		bar.EmptyColor = ""
+		return bar
+	}
+	return progress.New(
+		progress.WithGradient(currentTheme.ProgressStart, currentTheme.ProgressEnd),
+		progress.WithWidth(WinSize.Width),
+	)
+}
+
 func (m MainModel) Init() tea.Cmd {
 	return tea.Batch(tea.SetWindowTitle("tarragon"), m.spinner.Tick, refreshProjects)
 }
@@ -108,7 +178,7 @@ func (m MainModel) Init() tea.Cmd {
 func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
-	project, _ := m.table.model.HighlightedRow().Data[columnProject].(Project)
+	project := m.highlightedRow()
 	highlightedProject := matchProjectInMemory(project.Path, &m.projects)
 
 	switch msg := msg.(type) {
@@ -116,79 +186,189 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg
 		fmt.Printf("Error: %v\n", msg)
 		cmds = append(cmds, tea.Quit)
+	case tea.WindowSizeMsg:
+		WinSize.Width, WinSize.Height = msg.Width, msg.Height
+		m.resize()
 	case tea.KeyMsg:
-		if key.Matches(msg, m.keys.ToggleOutput) {
+		m.status = ""
+		if key.Matches(msg, m.keys.ToggleOutput) && (m.state == tableView || m.state == outputView) {
 			if m.state == outputView {
 				m.state = tableView
 			} else {
 				m.output.setTitle(project.Name, project.LastAction)
-				m.output.viewport.SetContent(project.Output + strings.Repeat("\n", 4))
+				m.output.setContent(project.Output)
 				m.state = outputView
 			}
 		}
-	}
 
-	switch m.state {
-	case tableView:
-		m.table.updateFooter()
-		m.table.model, cmd = m.table.model.Update(msg)
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		if m.working {
+			m.spinner, cmd = m.spinner.Update(msg)
+		}
 		cmds = append(cmds, cmd)
 
-		switch msg := msg.(type) {
-		case spinner.TickMsg:
-			var cmd tea.Cmd
-			if m.working {
-				m.spinner, cmd = m.spinner.Update(msg)
+	case progress.FrameMsg:
+		progressModel, cmd := m.progress.Update(msg)
+		m.progress = progressModel.(progress.Model)
+		cmds = append(cmds, cmd)
+
+	case RefreshFinishedMsg:
+		m.projects = msg
+		m.working = false
+		m.table.updateData(&m.projects)
+		if m.group != "" {
+			if patterns, err := resolveGroup(m.group); err == nil {
+				m.applyGroup(m.group, patterns)
 			}
-			cmds = append(cmds, cmd)
+			m.group = ""
+		}
 
-		case progress.FrameMsg:
-			progressModel, cmd := m.progress.Update(msg)
-			m.progress = progressModel.(progress.Model)
-			cmds = append(cmds, cmd)
+		if ValidateOnRefresh {
+			m.refreshing = true
+			m.working = true
+			m.message = "Terraform Validate: all projects"
 
-		case RefreshFinishedMsg:
-			m.projects = msg
-			m.working = false
-			m.table.updateData(&m.projects)
+			var batchArgs []tea.Cmd
+			batchArgs = append(batchArgs, m.spinner.Tick)
+			for i := range len(m.projects) {
+				batchArgs = append(batchArgs, runValidateAndFmtCheck(&m.projects[i]))
+			}
+			cmds = append(cmds, tea.Sequence(tea.Batch(batchArgs...), updatesFinished))
+		}
 
-			if ValidateOnRefresh {
-				m.refreshing = true
-				m.working = true
-				m.message = "Terraform Validate: all projects"
+	case UpdateValidateMsg:
+		m.message = fmt.Sprintf("Validated %s", msg.Name)
+		m.table.updateData(&m.projects)
+		if m.state == diagnosticsView && m.diagnostics.path == msg.Path {
+			m.diagnostics.setDiagnostics(msg.Diagnostics)
+		}
+		if m.refreshing {
+			m.percent += float64(1) / float64(m.table.model.TotalRows())
+		} else {
+			m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
+		}
 
-				var batchArgs []tea.Cmd
-				batchArgs = append(batchArgs, m.spinner.Tick)
-				for i := range len(m.projects) {
-					batchArgs = append(batchArgs, runValidate(&m.projects[i]))
-				}
-				cmds = append(cmds, tea.Sequence(tea.Batch(batchArgs...), updatesFinished))
-			}
+	case UpdatePlanMsg:
+		m.message = fmt.Sprintf("Updated %s", msg.Name)
+		m.table.updateData(&m.projects)
+		m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
 
-		case UpdateValidateMsg:
-			m.message = fmt.Sprintf("Validated %s", msg.Name)
-			m.table.updateData(&m.projects)
-			if m.refreshing {
-				m.percent += float64(1) / float64(m.table.model.TotalRows())
-			} else {
-				m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
-			}
+	case UpdateApplyMsg:
+		m.message = fmt.Sprintf("Applied %s", msg.Name)
+		m.table.updateData(&m.projects)
+		m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
 
-		case UpdatePlanMsg:
-			m.message = fmt.Sprintf("Updated %s", msg.Name)
-			m.table.updateData(&m.projects)
-			m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
+	case UpdateProvidersMsg:
+		m.message = fmt.Sprintf("Updated %s", msg.Name)
+		m.table.updateData(&m.projects)
+		m.percent += float64(1) / float64(max(len(m.table.model.SelectedRows()), 1))
+		if m.state == providersView {
+			m.providers.reload(m.projects)
+		}
 
-		case UpdateApplyMsg:
-			m.message = fmt.Sprintf("Applied %s", msg.Name)
-			m.table.updateData(&m.projects)
-			m.percent += float64(1) / float64(len(m.table.model.SelectedRows()))
+	case UpdateCommandMsg:
+		m.message = fmt.Sprintf("%s: %s", msg.LastAction, msg.Name)
+		m.table.updateData(&m.projects)
+		m.percent += float64(1) / float64(max(len(m.table.model.SelectedRows()), 1))
 
-		case UpdatesFinishedMsg:
-			m.working = false
-			m.refreshing = false
-			m.message = string(msg)
-			m.percent = 0.0
+	case UpdateFmtMsg:
+		m.message = fmt.Sprintf("Formatted %s", msg.Name)
+		m.table.updateData(&m.projects)
+		if m.state == fmtView || m.previous == fmtView {
+			m.fmt.setResult(Project(msg))
+		}
+		m.percent += float64(1) / float64(max(len(m.table.model.SelectedRows()), 1))
+
+	case FmtCheckedMsg:
+		m.working = false
+		m.message = ""
+		m.table.updateData(&m.projects)
+		if m.state != tableView {
+			// the results are kept in the projects, without taking over the
+			// view the user opened in the meantime
+			m.status = fmtSummary(msg.Results)
+			break
+		}
+		m.fmt = createFmtModel(msg.Results, WinSize.Width, WinSize.Height)
+		m.state = fmtView
+		if msg.Write {
+			m.confirmFmt()
+		}
+
+	case UpdatesFinishedMsg:
+		m.working = false
+		m.refreshing = false
+		m.message = string(msg)
+		m.percent = 0.0
+
+	case StateListMsg:
+		m.working = false
+		m.message = ""
+		project := matchProjectInMemory(msg.Path, &m.projects)
+		if project != nil {
+			m.stateBrowser = createStateModel(*project, msg.Resources, WinSize.Width, WinSize.Height)
+			m.state = stateView
+		}
+
+	case StateOperationMsg:
+		m.message = msg.Message
+		m.table.updateData(&m.projects)
+		if project := matchProjectInMemory(msg.Path, &m.projects); project != nil {
+			cmds = append(cmds, runStateList(project))
+		}
+
+	case OutputsMsg:
+		m.working = false
+		m.message = ""
+		if project := matchProjectInMemory(msg.Path, &m.projects); project != nil {
+			m.outputsPanel = createOutputsModel(*project, msg.Outputs, WinSize.Width, WinSize.Height)
+			m.state = outputsView
+		}
+
+	case ExecFinishedMsg:
+		if msg.Err != nil {
+			m.status = fmt.Sprintf("Could not run program: %s", msg.Err)
+			m.diagnostics.status = errorStyle.Render(m.status)
+			break
+		}
+		if project := matchProjectInMemory(msg.Path, &m.projects); project != nil {
+			m.working = true
+			m.message = fmt.Sprintf("Terraform Validate: %s", project.Name)
+			cmds = append(cmds, m.spinner.Tick, tea.Sequence(runValidate(project), updatesFinished))
+		}
+
+	case ReportExportedMsg:
+		m.working = false
+		m.message = ""
+		if msg.Err != nil {
+			m.status = fmt.Sprintf("Export failed: %v", msg.Err)
+		} else {
+			m.status = fmt.Sprintf("Report written to %s", msg.Path)
+		}
+
+	case StateShowMsg:
+		m.working = false
+		m.message = ""
+		if m.state == stateView && m.stateBrowser.path == msg.Path {
+			m.stateBrowser.showResource(msg.Resource)
+		}
+	}
+
+	switch m.state {
+	case tableView:
+		m.table.updateFooter()
+		if keyMsg, ok := msg.(tea.KeyMsg); !ok || !m.showTree || m.table.model.GetIsFilterInputFocused() || key.Matches(keyMsg, m.keys.Filter) {
+			m.table.model, cmd = m.table.model.Update(msg)
+			m.table.updateFilter(&m.projects)
+			cmds = append(cmds, cmd)
+		} else {
+			m.updateTree(keyMsg)
+		}
+
+		switch msg := msg.(type) {
+		case tea.MouseMsg:
+			m.updateMouse(msg)
 
 		case tea.KeyMsg:
 			if !m.table.model.GetIsFilterInputFocused() {
@@ -203,7 +383,19 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.working = true
 					cmds = append(cmds, m.spinner.Tick, refreshProjects)
 
-				case key.Matches(msg, m.keys.ValidateHighlighted):
+				case key.Matches(msg, m.keys.ToggleTree):
+					m.showTree = !m.showTree
+
+				case key.Matches(msg, m.keys.ToggleSplit):
+					m.toggleSplit()
+
+				case key.Matches(msg, m.keys.GrowSplit):
+					m.resizeSplit(splitStep)
+
+				case key.Matches(msg, m.keys.ShrinkSplit):
+					m.resizeSplit(-splitStep)
+
+				case key.Matches(msg, m.keys.ValidateHighlighted) && highlightedProject != nil:
 					m.working = true
 					m.message = fmt.Sprintf("Terraform Validate: %s", project.Name)
 					cmds = append(cmds, m.spinner.Tick, tea.Sequence(runValidate(highlightedProject), updatesFinished))
@@ -220,7 +412,7 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					cmds = append(cmds, tea.Sequence(tea.Batch(batchArgs...), updatesFinished))
 
-				case key.Matches(msg, m.keys.PlanHighlighted):
+				case key.Matches(msg, m.keys.PlanHighlighted) && highlightedProject != nil:
 					m.working = true
 					m.message = fmt.Sprintf("Terraform Plan: %s", project.Name)
 					cmds = append(cmds, m.spinner.Tick, tea.Sequence(runPlan(highlightedProject), updatesFinished))
@@ -237,15 +429,14 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					cmds = append(cmds, tea.Sequence(tea.Batch(batchArgs...), updatesFinished))
 
-				case key.Matches(msg, m.keys.ApplyHighlighted):
-					m.task = func(m *MainModel) tea.Cmd {
+				case key.Matches(msg, m.keys.ApplyHighlighted) && highlightedProject != nil:
+					m.confirm(applyWarning, func(m *MainModel) tea.Cmd {
 						m.message = fmt.Sprintf("Terraform Apply: %s", project.Name)
 						return tea.Sequence(runApply(highlightedProject), updatesFinished)
-					}
-					m.state = confirmationView
+					})
 
 				case key.Matches(msg, m.keys.ApplySelected):
-					m.task = func(m *MainModel) tea.Cmd {
+					m.confirm(applyWarning, func(m *MainModel) tea.Cmd {
 						m.message = "Terraform Apply: selected projects"
 
 						var batchArgs []tea.Cmd
@@ -254,8 +445,70 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							batchArgs = append(batchArgs, runApply(project))
 						}
 						return tea.Sequence(tea.Batch(batchArgs...), updatesFinished)
+					})
+
+				case key.Matches(msg, m.keys.InspectState):
+					if highlightedProject != nil {
+						m.working = true
+						m.message = fmt.Sprintf("Terraform State: %s", project.Name)
+						cmds = append(cmds, m.spinner.Tick, runStateList(highlightedProject))
 					}
-					m.state = confirmationView
+
+				case key.Matches(msg, m.keys.ShowOutputs):
+					if highlightedProject != nil {
+						m.working = true
+						m.message = fmt.Sprintf("Terraform Output: %s", project.Name)
+						cmds = append(cmds, m.spinner.Tick, runOutputs(highlightedProject))
+					}
+
+				case key.Matches(msg, m.keys.ShowProviders) && highlightedProject != nil:
+					m.providers = createProvidersModel(*highlightedProject, WinSize.Width, WinSize.Height)
+					m.state = providersView
+
+				case key.Matches(msg, m.keys.ForceUnlock):
+					if highlightedProject != nil && highlightedProject.Lock.ID != "" {
+						message := fmt.Sprintf("This will force-unlock %s", highlightedProject.Lock)
+						m.confirm(message, func(m *MainModel) tea.Cmd {
+							m.message = fmt.Sprintf("Terraform Force Unlock: %s", project.Name)
+							return tea.Sequence(runForceUnlock(highlightedProject), updatesFinished)
+						})
+					}
+
+				case key.Matches(msg, m.keys.ShowDiagnostics) && highlightedProject != nil:
+					if len(highlightedProject.Diagnostics) == 0 && highlightedProject.Valid != ConfigValid {
+						m.status = fmt.Sprintf("No diagnostics for %s, validate it first or check its output", project.Name)
+						break
+					}
+					m.diagnostics = createDiagnosticsModel(*highlightedProject, WinSize.Width, WinSize.Height)
+					m.state = diagnosticsView
+
+				case key.Matches(msg, m.keys.ShowFmtDiff) && highlightedProject != nil:
+					m.working = true
+					m.message = fmt.Sprintf("Terraform Fmt: %s", project.Name)
+					cmds = append(cmds, m.spinner.Tick, runFmtCheck([]*Project{highlightedProject}, false))
+
+				case key.Matches(msg, m.keys.FormatProjects):
+					if targets := m.fmtTargets(); len(targets) > 0 {
+						m.working = true
+						m.message = fmt.Sprintf("Terraform Fmt: %d projects", len(targets))
+						cmds = append(cmds, m.spinner.Tick, runFmtCheck(targets, true))
+					}
+
+				case key.Matches(msg, m.keys.OpenEditor) && highlightedProject != nil:
+					cmds = append(cmds, openEditor(*highlightedProject))
+
+				case key.Matches(msg, m.keys.OpenShell) && highlightedProject != nil:
+					cmds = append(cmds, openShell(*highlightedProject))
+
+				case key.Matches(msg, m.keys.ExportReport):
+					m.formatPicker = createFormatPicker()
+					m.state = exportView
+
+				case key.Matches(msg, m.keys.SortColumn) && !m.showTree:
+					m.table.cycleSort()
+
+				case key.Matches(msg, m.keys.SortOrder) && !m.showTree:
+					m.table.reverseSort()
 
 				case key.Matches(msg, m.keys.SelectAll):
 					rows := m.table.model.GetVisibleRows()
@@ -264,7 +517,40 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 
 				case key.Matches(msg, m.keys.DeselectAll):
-					m.table.model.WithAllRowsDeselected()
+					m.table.selectPaths(&m.projects, []string{})
+
+				case key.Matches(msg, m.keys.ApplyGroup):
+					groups, err := loadGroups(groupsPath())
+					if err != nil {
+						m.status = fmt.Sprintf("Could not load groups: %v", err)
+						break
+					}
+					if len(groups) == 0 {
+						m.status = fmt.Sprintf("No groups yet, select projects and press %s to save them as one", m.keys.SaveGroup.Help().Key)
+						break
+					}
+					m.groups = groups
+					m.groupPicker = createGroupPicker(groups)
+					m.state = groupView
+
+				case key.Matches(msg, m.keys.ChooseProfile):
+					if len(Settings.Profiles) == 0 {
+						m.status = "No profiles, add them under profiles in the config file"
+						break
+					}
+					m.profilePicker = createProfilePicker(Settings.Profiles)
+					m.state = profileView
+
+				case key.Matches(msg, m.keys.SaveGroup):
+					if len(m.table.visibleSelectedPaths()) == 0 {
+						m.status = "Select the projects to save as a group first"
+						break
+					}
+					m.groupInput = createGroupInput()
+					m.state = saveGroupView
+
+				default:
+					cmds = append(cmds, m.startCustomCommand(msg))
 				}
 			}
 		}
@@ -273,16 +559,16 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		msg, _ := msg.(tea.KeyMsg)
 		switch {
 		case key.Matches(msg, m.keys.Cancel):
-			m.state = tableView
+			m.state = m.previous
 			m.working = false
 
 		case key.Matches(msg, m.keys.No):
-			m.state = tableView
+			m.state = m.previous
 			m.working = false
 
 		case key.Matches(msg, m.keys.Yes):
 			cmds = append(cmds, m.spinner.Tick, m.task(&m))
-			m.state = tableView
+			m.state = m.previous
 			m.working = true
 
 		default:
@@ -291,13 +577,348 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case outputView:
-		m.output.viewport, cmd = m.output.viewport.Update(msg)
-		cmds = append(cmds, cmd)
+		if m.output.searching() {
+			keyMsg, _ := msg.(tea.KeyMsg)
+			switch {
+			case key.Matches(keyMsg, m.keys.Cancel):
+				m.output.clearSearch()
+
+			case key.Matches(keyMsg, m.keys.Submit):
+				m.output.input.Blur()
+
+			default:
+				m.output.input, cmd = m.output.input.Update(msg)
+				m.output.search(m.output.input.Value())
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+
+		keyMsg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(keyMsg, m.keys.Filter):
+			cmds = append(cmds, m.output.startSearch())
+
+		case key.Matches(keyMsg, m.keys.Cancel):
+			m.output.clearSearch()
+
+		case key.Matches(keyMsg, m.keys.NextMatch):
+			m.output.nextMatch(1)
+
+		case key.Matches(keyMsg, m.keys.PrevMatch):
+			m.output.nextMatch(-1)
+
+		case key.Matches(keyMsg, m.keys.NextError):
+			m.output.jumpTo(errorLinePattern, 1)
+
+		case key.Matches(keyMsg, m.keys.PrevError):
+			m.output.jumpTo(errorLinePattern, -1)
+
+		case key.Matches(keyMsg, m.keys.NextChange):
+			m.output.jumpTo(changeLinePattern, 1)
+
+		case key.Matches(keyMsg, m.keys.PrevChange):
+			m.output.jumpTo(changeLinePattern, -1)
+
+		default:
+			m.output.viewport, cmd = m.output.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case diagnosticsView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up):
+			m.diagnostics.moveCursor(-1)
+
+		case key.Matches(msg, m.keys.Down):
+			m.diagnostics.moveCursor(1)
+
+		case key.Matches(msg, m.keys.EditFile):
+			cmds = append(cmds, m.diagnostics.openInEditor())
+		}
+
+	case fmtView:
+		keyMsg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(keyMsg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(keyMsg, m.keys.FormatProjects):
+			m.confirmFmt()
+
+		default:
+			m.fmt.viewport, cmd = m.fmt.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case exportView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Submit):
+			format, err := m.formatPicker.Value()
+			if err == nil {
+				m.working = true
+				m.message = fmt.Sprintf("Exporting %s report", format)
+				cmds = append(cmds, m.spinner.Tick, exportReport(m.table.visibleProjects(), format))
+			}
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.Down):
+			m.formatPicker.Update(msg)
+		}
+
+	case groupView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Submit):
+			if name, err := m.groupPicker.Value(); err == nil {
+				m.applyGroup(name, m.groups[name])
+			}
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.Down):
+			m.groupPicker.Update(msg)
+		}
+
+	case profileView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Submit):
+			if name, err := m.profilePicker.Value(); err == nil {
+				m.applyProfile(name, m.actionTargets())
+			}
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.Down):
+			m.profilePicker.Update(msg)
+		}
+
+	case saveGroupView:
+		keyMsg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(keyMsg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(keyMsg, m.keys.Submit):
+			m.saveSelection(m.groupInput.Value())
+			m.state = tableView
+
+		default:
+			m.groupInput, cmd = m.groupInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case outputsView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up):
+			m.outputsPanel.moveCursor(-1)
+
+		case key.Matches(msg, m.keys.Down):
+			m.outputsPanel.moveCursor(1)
+
+		case key.Matches(msg, m.keys.Reveal):
+			m.outputsPanel.toggleReveal()
+
+		case key.Matches(msg, m.keys.Copy):
+			m.outputsPanel.copyValue()
+		}
+
+	case providersView:
+		msg, _ := msg.(tea.KeyMsg)
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = tableView
+
+		case key.Matches(msg, m.keys.Up):
+			m.providers.moveCursor(-1)
+
+		case key.Matches(msg, m.keys.Down):
+			m.providers.moveCursor(1)
+
+		case key.Matches(msg, m.keys.ToggleMatrix):
+			m.providers.toggleMatrix(m.projects)
+
+		case key.Matches(msg, m.keys.LockProviders):
+			m.confirmProviders(ProvidersLock, func(project *Project) tea.Cmd {
+				return runProvidersLock(project, Settings.LockPlatforms)
+			})
+
+		case key.Matches(msg, m.keys.UpgradeProviders):
+			m.confirmProviders(InitUpgrade, runInitUpgrade)
+		}
+
+	case stateView:
+		if m.stateBrowser.showingResource() {
+			msg, _ := msg.(tea.KeyMsg)
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.stateBrowser.closeResource()
+
+			case key.Matches(msg, m.keys.Up):
+				m.stateBrowser.moveCursor(-1)
+
+			case key.Matches(msg, m.keys.Down):
+				m.stateBrowser.moveCursor(1)
+
+			case key.Matches(msg, m.keys.Open):
+				m.stateBrowser.toggleNode()
+			}
+			break
+		}
+
+		if m.stateBrowser.prompting() {
+			keyMsg, _ := msg.(tea.KeyMsg)
+			switch {
+			case key.Matches(keyMsg, m.keys.Cancel):
+				m.stateBrowser.input.Blur()
+
+			case key.Matches(keyMsg, m.keys.Submit):
+				operation, cmd := m.stateBrowser.submitPrompt()
+				if cmd != nil {
+					cmds = append(cmds, cmd)
+				} else if operation.Argument != "" {
+					m.confirmStateOperation(operation)
+				}
+
+			default:
+				m.stateBrowser.input, cmd = m.stateBrowser.input.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+
+		if msg, ok := msg.(tea.KeyMsg); ok && !m.stateBrowser.resources.GetIsFilterInputFocused() {
+			address := m.stateBrowser.highlightedAddress()
+
+			switch {
+			case key.Matches(msg, m.keys.Cancel) && m.stateBrowser.resources.GetCurrentFilter() == "":
+				m.state = tableView
+
+			case key.Matches(msg, m.keys.Import):
+				cmds = append(cmds, m.stateBrowser.startPrompt(StateOperation{Command: Import}))
+
+			case address == "":
+
+			case key.Matches(msg, m.keys.StateMove):
+				cmds = append(cmds, m.stateBrowser.startPrompt(StateOperation{Command: StateMove, Address: address}))
+
+			case key.Matches(msg, m.keys.StateRemove):
+				m.confirmStateOperation(StateOperation{Command: StateRemove, Address: address})
+
+			case key.Matches(msg, m.keys.Taint):
+				m.confirmStateOperation(StateOperation{Command: Taint, Address: address})
+
+			case key.Matches(msg, m.keys.Untaint):
+				m.confirmStateOperation(StateOperation{Command: Untaint, Address: address})
+
+			case key.Matches(msg, m.keys.Open):
+				stateProject := matchProjectInMemory(m.stateBrowser.path, &m.projects)
+				if stateProject != nil {
+					m.working = true
+					m.message = fmt.Sprintf("Terraform State: %s", address)
+					cmds = append(cmds, m.spinner.Tick, runStateShow(stateProject, address))
+				}
+			}
+		}
+
+		if !m.stateBrowser.prompting() {
+			m.stateBrowser.resources, cmd = m.stateBrowser.resources.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	}
 
+	m.updatePane()
 	return m, tea.Batch(cmds...)
 }
 
+// highlightedRow returns the project under the cursor of the table or, if
+// toggled, the tree. It is empty if a folder or nothing is highlighted.
+func (m *MainModel) highlightedRow() Project {
+	if m.showTree {
+		if node := m.tree.highlighted(m.table.visibleProjects()); node != nil && node.Project != nil {
+			return *node.Project
+		}
+		return Project{}
+	}
+	project, _ := m.table.model.HighlightedRow().Data[columnProject].(Project)
+	return project
+}
+
+// actionTargets returns the paths of the projects an action in the table or
+// tree runs in: the selected projects or, if none are selected, the
+// highlighted one.
+func (m *MainModel) actionTargets() []string {
+	if paths := m.table.visibleSelectedPaths(); len(paths) > 0 {
+		return paths
+	}
+	if project := m.highlightedRow(); project.Path != "" {
+		return []string{project.Path}
+	}
+	return []string{}
+}
+
+// updateTree moves around the tree view. Selecting a group selects every
+// project beneath it, so the selected actions run on the whole group.
+func (m *MainModel) updateTree(msg tea.KeyMsg) {
+	projects := m.table.visibleProjects()
+	height := treeHeight(m.layout().ProjectsHeight)
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.tree.moveCursor(projects, -1, height)
+
+	case key.Matches(msg, m.keys.Down):
+		m.tree.moveCursor(projects, 1, height)
+
+	case key.Matches(msg, m.keys.Expand):
+		m.tree.setCollapsed(projects, false)
+
+	case key.Matches(msg, m.keys.Collapse):
+		m.tree.setCollapsed(projects, true)
+
+	case key.Matches(msg, m.keys.Select):
+		m.table.selectPaths(&m.projects, m.tree.toggleSelection(projects, m.table.selectedPaths()))
+	}
+}
+
+// confirm asks the user to approve a task before it is run. The current view
+// is restored once the prompt is answered.
+func (m *MainModel) confirm(message string, task func(*MainModel) tea.Cmd) {
+	m.confirmation = createConfirmation(message)
+	m.task = task
+	m.previous = m.state
+	m.state = confirmationView
+}
+
+func (m *MainModel) confirmStateOperation(operation StateOperation) {
+	project := matchProjectInMemory(m.stateBrowser.path, &m.projects)
+	if project == nil {
+		return
+	}
+
+	message := fmt.Sprintf("This will back up the state and run `%s`", operation)
+	m.confirm(message, func(m *MainModel) tea.Cmd {
+		m.message = fmt.Sprintf("Terraform %s: %s", operation.Command, project.Name)
+		return runStateOperation(project, operation)
+	})
+}
+
 func (m MainModel) renderProgress() string {
 	working := ""
 	progress := ""
@@ -306,26 +927,83 @@ func (m MainModel) renderProgress() string {
 		if len(m.table.model.SelectedRows()) > 1 || m.refreshing {
 			progress = m.progress.ViewAs(m.percent)
 		}
+	} else if m.status != "" {
+		working = tableDate.Render(" " + m.status)
 	}
 	return working + "\n" + progress
 }
 
+// renderProjects renders the projects as a table or, if toggled, as a tree,
+// together with the output pane if the split is on.
+func (m MainModel) renderProjects() string {
+	if m.showTree {
+		layout := m.layout()
+		return m.renderSplit(m.tree.renderTree(
+			m.table.visibleProjects(),
+			m.table.selectedPaths(),
+			m.table.renderQuery(),
+			layout.ProjectsWidth,
+			treeHeight(layout.ProjectsHeight),
+		))
+	}
+	return m.renderSplit(m.table.renderTable())
+}
+
 func (m MainModel) View() string {
 	var output string
 
 	switch m.state {
 	case tableView:
-		table := m.table.renderTable()
+		table := m.renderProjects()
 		progress := m.renderProgress()
 		helpView := m.help.View(m.keys)
+		if m.showTree {
+			helpView = m.help.View(m.keys.TreeHelp())
+		}
 
 		contentHeight := lipgloss.Height(table) + lipgloss.Height(progress)
 		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(helpView)
 
 		output = table + progress + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
 
+	case exportView, groupView, profileView:
+		table := m.renderProjects()
+		progress := m.renderProgress()
+		picker := m.formatPicker.View()
+		switch m.state {
+		case groupView:
+			picker = m.groupPicker.View()
+		case profileView:
+			picker = m.profilePicker.View()
+		}
+		helpView := m.help.View(m.keys.PickerHelp())
+
+		contentHeight := lipgloss.Height(table) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(picker) - lipgloss.Height(helpView)
+
+		output = table + progress + strings.Repeat("\n", max(paddingHeight, 0)) + picker + "\n" + helpView
+
+	case saveGroupView:
+		table := m.renderProjects()
+		progress := m.renderProgress()
+		input := m.groupInput.View()
+		helpView := m.help.View(m.keys.PromptHelp())
+
+		contentHeight := lipgloss.Height(table) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(input) - lipgloss.Height(helpView)
+
+		output = table + progress + strings.Repeat("\n", max(paddingHeight, 0)) + input + "\n" + helpView
+
 	case confirmationView:
-		table := m.table.renderTable()
+		table := m.renderProjects()
+		switch m.previous {
+		case stateView:
+			table = m.stateBrowser.renderState()
+		case providersView:
+			table = m.providers.renderProviders()
+		case fmtView:
+			table = m.fmt.renderFmt()
+		}
 		progress := m.renderProgress()
 		confirm := m.confirmation.View()
 
@@ -336,6 +1014,53 @@ func (m MainModel) View() string {
 
 	case outputView:
 		output = m.output.renderOutput()
+
+	case outputsView:
+		outputs := m.outputsPanel.renderOutputs()
+		helpView := m.help.View(m.keys.OutputsHelp())
+		paddingHeight := WinSize.Height - lipgloss.Height(outputs) - lipgloss.Height(helpView)
+
+		output = outputs + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
+
+	case providersView:
+		providers := m.providers.renderProviders()
+		progress := m.renderProgress()
+		helpView := m.help.View(m.keys.ProvidersHelp())
+
+		contentHeight := lipgloss.Height(providers) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(helpView)
+
+		output = providers + progress + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
+
+	case fmtView:
+		fmtDiff := m.fmt.renderFmt()
+		progress := m.renderProgress()
+		helpView := m.help.View(m.keys.FmtHelp())
+
+		contentHeight := lipgloss.Height(fmtDiff) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(helpView)
+
+		output = fmtDiff + progress + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
+
+	case diagnosticsView:
+		diagnostics := m.diagnostics.renderDiagnostics()
+		progress := m.renderProgress()
+		helpView := m.help.View(m.keys.DiagnosticsHelp())
+
+		contentHeight := lipgloss.Height(diagnostics) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(helpView)
+
+		output = diagnostics + progress + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
+
+	case stateView:
+		state := m.stateBrowser.renderState()
+		progress := m.renderProgress()
+		helpView := m.help.View(m.keys.StateHelp(m.stateBrowser.showingResource()))
+
+		contentHeight := lipgloss.Height(state) + lipgloss.Height(progress)
+		paddingHeight := WinSize.Height - contentHeight - lipgloss.Height(helpView)
+
+		output = state + progress + strings.Repeat("\n", max(paddingHeight, 0)) + helpView
 	}
 	return output
 }
@@ -349,9 +1074,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "outputs" {
+		if err := runOutputsCommand(os.Args[2:], os.Stdout, cwd); err != nil {
+			fmt.Printf("Uh oh, there was an error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	flag.BoolVar(&versionFlag, "version", false, "Show version number")
 	flag.BoolVar(&Debug, "debug", false, "Enable logging to file (debug.log)")
+	flag.BoolVar(&Mouse, "mouse", false, "Enable mouse support")
 	flag.StringVar(&SearchPath, "path", cwd, "Path to search for Terraform projects")
+	flag.StringVar(&ConfigPath, "config", defaultConfigPath(), "Path to the config file")
+	flag.StringVar(&ExportPath, "export", "", "Write a report to this file (- for stdout) without starting the UI")
+	flag.StringVar(&ExportFormat, "format", "", "Report format: markdown, html, junit or sarif (default: from the file extension)")
+	flag.BoolVar(&ExportPlan, "plan", false, "Also plan every project before exporting the report")
+	flag.StringVar(&GroupName, "group", "", "Select the projects of this group, or only export them with --export")
 	flag.Parse()
 
 	if versionFlag {
@@ -359,6 +1098,54 @@ func main() {
 		os.Exit(0)
 	}
 
+	Settings, err = loadConfig(ConfigPath)
+	if err != nil {
+		fmt.Printf("Uh oh, there was an error loading the config: %v\n", err)
+		os.Exit(1)
+	}
+
+	theme, err := resolveTheme(Settings.Theme, Settings.Themes)
+	if err != nil {
+		fmt.Printf("Uh oh, there was an error loading the theme: %v\n", err)
+		os.Exit(1)
+	}
+	applyTheme(theme)
+
+	mainKeys, err = mainKeys.withOverrides(Settings.Keys)
+	if err != nil {
+		fmt.Printf("Uh oh, there was an error loading the key bindings: %v\n", err)
+		os.Exit(1)
+	}
+	mainKeys.Commands = commandBindings(Settings.Commands)
+	if conflicts := mainKeys.conflicts(); len(conflicts) > 0 {
+		fmt.Println("Uh oh, there are conflicting key bindings:")
+		for _, conflict := range conflicts {
+			fmt.Printf("  %s\n", conflict)
+		}
+		os.Exit(1)
+	}
+
+	var group []string
+	if GroupName != "" {
+		group, err = resolveGroup(GroupName)
+		if err != nil {
+			fmt.Printf("Uh oh, there was an error loading the group: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if ExportPath != "" {
+		format := ReportFormat(ExportFormat)
+		if format == "" {
+			format = reportFormatFromPath(ExportPath)
+		}
+		if err := runHeadlessExport(ExportPath, format, ExportPlan, group); err != nil {
+			fmt.Printf("Uh oh, there was an error exporting the report: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if Debug {
 		log.SetFlags(log.Lshortfile | log.Ldate | log.Ltime)
 		f, err := tea.LogToFile("debug.log", "debug")
@@ -369,10 +1156,12 @@ func main() {
 		defer f.Close()
 	}
 
-	p := tea.NewProgram(
-		initialModel(),
-		tea.WithAltScreen(),
-	)
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if Mouse || Settings.Mouse {
+		options = append(options, tea.WithMouseCellMotion())
+	}
+
+	p := tea.NewProgram(initialModel(), options...)
 	_, runErr := p.Run()
 	if runErr != nil {
 		fmt.Printf("Uh oh, there was an error: %v\n", err)
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// tableHeaderTop and tableRowsTop are the lines of the screen with the
+	// column headers and the first row of the projects table.
+	tableHeaderTop = paneOffset + 1
+	tableRowsTop   = paneOffset + 3
+
+	// treeRowsTop is the line of the screen with the first row of the tree.
+	treeRowsTop = paneOffset + 1
+
+	// treeSelectionWidth is the width of the selection marks on the left of
+	// the tree, inside the border.
+	treeSelectionWidth = 3
+)
+
+// updateMouse handles clicks and the mouse wheel in the table view. Clicking
+// a row highlights it and clicking its selection column toggles it, clicking
+// a column header sorts by that column, and the wheel moves the cursor, or
+// scrolls the output pane when the pointer is over it.
+func (m *MainModel) updateMouse(msg tea.MouseMsg) {
+	if m.inPane(msg) {
+		if tea.MouseEvent(msg).IsWheel() {
+			m.pane.viewport, _ = m.pane.viewport.Update(msg)
+		}
+		return
+	}
+	if m.table.model.GetIsFilterInputFocused() {
+		return
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.scrollProjects(-1)
+
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.scrollProjects(1)
+
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		if m.showTree {
+			m.clickTree(msg.X, msg.Y)
+		} else {
+			m.clickTable(msg.X, msg.Y)
+		}
+	}
+}
+
+// inPane reports whether the pointer is over the output pane of the split
+// layout.
+func (m *MainModel) inPane(msg tea.MouseMsg) bool {
+	layout := m.layout()
+	switch m.split {
+	case SplitHorizontal:
+		return msg.X > layout.ProjectsWidth
+	case SplitVertical:
+		return msg.Y >= layout.ProjectsHeight
+	default:
+		return false
+	}
+}
+
+func (m *MainModel) scrollProjects(delta int) {
+	if m.showTree {
+		m.tree.moveCursor(m.table.visibleProjects(), delta, treeHeight(m.layout().ProjectsHeight))
+		return
+	}
+	m.table.model = m.table.model.WithHighlightedRow(m.table.model.GetHighlightedRowIndex() + delta)
+	m.table.updateFooter()
+}
+
+func (m *MainModel) clickTable(x int, y int) {
+	if y == tableHeaderTop {
+		if column := m.table.columnAt(x); column > 0 {
+			m.table.sortBy(m.table.shownColumns()[column-1])
+		}
+		return
+	}
+
+	row := m.table.rowAt(y)
+	if row < 0 {
+		return
+	}
+	m.table.model = m.table.model.WithHighlightedRow(row)
+	m.table.updateFooter()
+
+	if m.table.columnAt(x) == 0 {
+		project := m.table.model.GetVisibleRows()[row].Data[columnProject].(Project)
+		m.table.selectPaths(&m.projects, toggleString(m.table.selectedPaths(), project.Path))
+	}
+}
+
+func (m *MainModel) clickTree(x int, y int) {
+	projects := m.table.visibleProjects()
+	if !m.tree.clickRow(projects, y-treeRowsTop, treeHeight(m.layout().ProjectsHeight)) {
+		return
+	}
+	if x >= 1 && x <= treeSelectionWidth {
+		m.table.selectPaths(&m.projects, m.tree.toggleSelection(projects, m.table.selectedPaths()))
+	}
+}
+
+// rowAt returns the index among the visible rows of the table row on line y
+// of the screen, or -1 if there is none.
+func (m *TableModel) rowAt(y int) int {
+	start, end := m.model.VisibleIndices()
+	row := start + y - tableRowsTop
+	if y < tableRowsTop || row > end {
+		return -1
+	}
+	return row
+}
+
+// columnAt returns the table column at x: 0 for the selection column and i
+// for the i-th shown column. It returns -1 for the borders.
+func (m *TableModel) columnAt(x int) int {
+	lines := strings.Split(removeANSIEscapeCodes(m.model.View()), "\n")
+	if len(lines) < 2 {
+		return -1
+	}
+
+	column := -1
+	for i, r := range []rune(lines[1]) {
+		if r == '│' {
+			if i == x {
+				return -1
+			}
+			column++
+		} else if i == x {
+			return column
+		}
+	}
+	return -1
+}
+
+// sortBy sorts the table by a column, or flips the order if the table is
+// already sorted by it.
+func (m *TableModel) sortBy(column string) {
+	if column == m.sortColumn {
+		m.reverseSort()
+		return
+	}
+	m.sortColumn = column
+	m.sortDesc = false
+	m.applySort()
+}
+
+// toggleString adds value to values, or removes it if it is already there.
+func toggleString(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return slices.DeleteFunc(values, func(v string) bool { return v == value })
+	}
+	return append(values, value)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func mouseModel() MainModel {
+	WinSize.Width, WinSize.Height = 120, 30
+	m := initialModel()
+	for i := range 5 {
+		m.projects = append(m.projects, Project{
+			Name:        fmt.Sprintf("project%d", i),
+			Path:        fmt.Sprintf("/services/project%d", i),
+			PlanChanges: TerraformChanges{0, 0, i},
+		})
+	}
+	m.table.updateData(&m.projects)
+	return m
+}
+
+func click(m MainModel, x int, y int) MainModel {
+	model, _ := m.Update(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	return model.(MainModel)
+}
+
+func TestMouse(t *testing.T) {
+	t.Run("Click highlights a row", func(t *testing.T) {
+		m := click(mouseModel(), 20, tableRowsTop+2)
+		if got := m.highlightedRow().Name; got != "project2" {
+			t.Errorf("Expected project2 to be highlighted, got %s", got)
+		}
+	})
+
+	t.Run("Click on the selection column toggles it", func(t *testing.T) {
+		m := click(mouseModel(), 2, tableRowsTop+1)
+		if got := m.table.selectedPaths(); len(got) != 1 || got[0] != "/services/project1" {
+			t.Errorf("Expected project1 to be selected, got %v", got)
+		}
+		m = click(m, 2, tableRowsTop+1)
+		if got := m.table.selectedPaths(); len(got) != 0 {
+			t.Errorf("Expected project1 to be deselected, got %v", got)
+		}
+	})
+
+	t.Run("Click on a header sorts", func(t *testing.T) {
+		m := mouseModel()
+		header := strings.Split(removeANSIEscapeCodes(m.table.model.View()), "\n")[1]
+		x := len([]rune(header[:strings.Index(header, "Destroy")]))
+
+		m = click(m, x, tableHeaderTop)
+		if m.table.sortColumn != "destroy" || m.table.sortDesc {
+			t.Errorf("Expected to sort by destroy ascending, got %s (descending: %t)", m.table.sortColumn, m.table.sortDesc)
+		}
+		m = click(m, x, tableHeaderTop)
+		assertRowOrder(t, m.table, "project4", "project3")
+	})
+
+	t.Run("Wheel moves the cursor", func(t *testing.T) {
+		model, _ := mouseModel().Update(tea.MouseMsg{X: 20, Y: 10, Button: tea.MouseButtonWheelDown})
+		m := model.(MainModel)
+		if got := m.highlightedRow().Name; got != "project1" {
+			t.Errorf("Expected project1 to be highlighted, got %s", got)
+		}
+	})
+}
@@ -2,32 +2,220 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+var (
+	errorLinePattern  = regexp.MustCompile(`^[│╷\s]*Error:`)
+	changeLinePattern = regexp.MustCompile(`^\s*(# \S+ (will be|must be|has changed|has been)|\S+: (Creating|Modifying|Destroying)\.\.\.)`)
+)
+
 type OutputModel struct {
 	title    string
 	action   TerraformCommand
 	viewport viewport.Model
 	width    int
 	height   int
+	content  string
+	lines    []string
+	input    textinput.Model
+	query    string
+	matches  []OutputMatch
+	current  int
+	position int
+}
+
+// OutputMatch is a search match in the output, as byte offsets into a line
+// with the ANSI escape codes removed.
+type OutputMatch struct {
+	Line  int
+	Start int
+	End   int
 }
 
 func (m *OutputModel) createViewport() {
 	vpHeaderHeight := lipgloss.Height(m.outputHeader())
 	vp := viewport.New(m.width, m.height-vpHeaderHeight*2)
 	vp.YPosition = vpHeaderHeight + 1
+	vp.KeyMap = mainKeys.viewportKeyMap()
 	m.viewport = vp
 }
 
+func (m *OutputModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-lipgloss.Height(m.outputHeader())*2, 1)
+}
+
 func (m *OutputModel) setTitle(title string, lastAction TerraformCommand) {
 	m.title = title
 	m.action = lastAction
 }
 
+// setContent shows new output and clears the previous search.
+func (m *OutputModel) setContent(content string) {
+	m.content = content
+	m.lines = strings.Split(removeANSIEscapeCodes(content), "\n")
+	m.input = textinput.New()
+	m.query = ""
+	m.matches = nil
+	m.position = -1
+	m.renderContent()
+}
+
+func (m *OutputModel) renderContent() {
+	if m.query == "" {
+		m.viewport.SetContent(m.content + strings.Repeat("\n", 4))
+		return
+	}
+
+	lines := slices.Clone(m.lines)
+	for i := len(m.matches) - 1; i >= 0; i-- {
+		match := m.matches[i]
+		style := searchMatch
+		if i == m.current {
+			style = searchCurrent
+		}
+		line := lines[match.Line]
+		lines[match.Line] = line[:match.Start] + style.Render(line[match.Start:match.End]) + line[match.End:]
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n") + strings.Repeat("\n", 4))
+}
+
+// findMatches searches the output for query. The search ignores case unless
+// the query contains an upper case letter.
+func findMatches(lines []string, query string) []OutputMatch {
+	matches := []OutputMatch{}
+	if query == "" {
+		return matches
+	}
+
+	foldCase := !strings.ContainsFunc(query, unicode.IsUpper)
+	if foldCase {
+		query = strings.ToLower(query)
+	}
+
+	for i, line := range lines {
+		for start := 0; start < len(line); {
+			if end, ok := matchAt(line, start, query, foldCase); ok {
+				matches = append(matches, OutputMatch{Line: i, Start: start, End: end})
+				start = end
+				continue
+			}
+			_, size := utf8.DecodeRuneInString(line[start:])
+			start += size
+		}
+	}
+	return matches
+}
+
+// matchAt reports whether query matches line at the byte offset start and
+// returns where the match ends. Folding compares the lines rune by rune, as
+// lowercasing can change the byte length of a line.
+func matchAt(line string, start int, query string, foldCase bool) (int, bool) {
+	if !foldCase {
+		return start + len(query), strings.HasPrefix(line[start:], query)
+	}
+
+	end := start
+	for _, want := range query {
+		r, size := utf8.DecodeRuneInString(line[end:])
+		if size == 0 || unicode.ToLower(r) != want {
+			return 0, false
+		}
+		end += size
+	}
+	return end, true
+}
+
+func (m *OutputModel) startSearch() tea.Cmd {
+	m.input = textinput.New()
+	m.input.Prompt = "/"
+	m.input.SetValue(m.query)
+	return m.input.Focus()
+}
+
+func (m *OutputModel) searching() bool {
+	return m.input.Focused()
+}
+
+// search updates the matches while the query is typed and scrolls to the
+// first match below the top of the view.
+func (m *OutputModel) search(query string) {
+	m.query = query
+	m.matches = findMatches(m.lines, query)
+	m.current = 0
+	for i, match := range m.matches {
+		if match.Line >= m.viewport.YOffset {
+			m.current = i
+			break
+		}
+	}
+	m.showMatch()
+}
+
+func (m *OutputModel) clearSearch() {
+	m.input.Blur()
+	m.search("")
+}
+
+func (m *OutputModel) nextMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.current = (m.current + delta + len(m.matches)) % len(m.matches)
+	m.showMatch()
+}
+
+func (m *OutputModel) showMatch() {
+	m.renderContent()
+	if len(m.matches) > 0 {
+		m.scrollTo(m.matches[m.current].Line)
+	}
+}
+
+// scrollTo keeps a line in view, leaving a few lines of context above it.
+func (m *OutputModel) scrollTo(line int) {
+	m.position = line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(max(line-2, 0))
+	}
+}
+
+// jumpTo scrolls to the next (or previous) line of the output that matches
+// pattern, such as the next error or resource change.
+func (m *OutputModel) jumpTo(pattern *regexp.Regexp, delta int) {
+	if len(m.lines) == 0 {
+		return
+	}
+
+	position := m.position
+	if position < m.viewport.YOffset || position >= m.viewport.YOffset+m.viewport.Height {
+		position = m.viewport.YOffset - 1
+		if delta < 0 {
+			position = m.viewport.YOffset + m.viewport.Height
+		}
+	}
+
+	for i := range m.lines {
+		line := (position + delta*(i+1) + 2*len(m.lines)) % len(m.lines)
+		if pattern.MatchString(m.lines[line]) {
+			m.scrollTo(line)
+			return
+		}
+	}
+}
+
 func (m *OutputModel) outputHeader() string {
 	title := outputTitle.Render(fmt.Sprintf("Output (%s): %s", m.action, m.title))
 	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
@@ -35,10 +223,28 @@ func (m *OutputModel) outputHeader() string {
 	return header
 }
 
+func (m *OutputModel) searchStatus() string {
+	if m.query == "" && !m.searching() {
+		return ""
+	}
+
+	counter := "no matches"
+	if len(m.matches) > 0 {
+		counter = fmt.Sprintf("%d/%d", m.current+1, len(m.matches))
+	}
+
+	query := tableFilterSet.Render("/" + m.query)
+	if m.searching() {
+		query = tableFilterTyping.Render(m.input.View())
+	}
+	return fmt.Sprintf(" %s  %s ", query, tableDate.Render(counter))
+}
+
 func (m *OutputModel) outputFooter() string {
+	search := m.searchStatus()
 	info := outputInfo.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
-	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(info)))
-	footer := lipgloss.JoinHorizontal(lipgloss.Center, line, info)
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(info)-lipgloss.Width(search)))
+	footer := lipgloss.JoinHorizontal(lipgloss.Center, search, line, info)
 	return footer
 }
 
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindMatches(t *testing.T) {
+	lines := []string{"aws_instance.web will be created", "Instance type: t3.micro", "aws_instance.db"}
+
+	t.Run("Ignores case", func(t *testing.T) {
+		got := findMatches(lines, "instance")
+		want := []OutputMatch{{0, 4, 12}, {1, 0, 8}, {2, 4, 12}}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Smart case", func(t *testing.T) {
+		got := findMatches(lines, "Instance")
+		want := []OutputMatch{{1, 0, 8}}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Ignores case when lowercasing changes the length", func(t *testing.T) {
+		got := findMatches([]string{"İstanbul: aws_s3_bucket.LOGS"}, "logs")
+		want := []OutputMatch{{0, 25, 29}}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		if got := findMatches(lines, ""); len(got) != 0 {
+			t.Errorf("Expected no matches, got %v", got)
+		}
+	})
+}
+
+func TestOutputJumps(t *testing.T) {
+	output := []string{
+		"Terraform will perform the following actions:",
+		"  # aws_instance.web will be created",
+		"  # aws_s3_bucket.logs must be replaced",
+		"╷",
+		"│ \x1b[31mError:\x1b[0m Unsupported argument",
+		"╵",
+	}
+	m := OutputModel{width: 80, height: 100}
+	m.createViewport()
+	m.setContent(strings.Join(output, "\n"))
+
+	m.jumpTo(changeLinePattern, 1)
+	if m.position != 1 {
+		t.Errorf("Expected the first change on line 1, got %d", m.position)
+	}
+	m.jumpTo(changeLinePattern, 1)
+	if m.position != 2 {
+		t.Errorf("Expected the next change on line 2, got %d", m.position)
+	}
+	m.jumpTo(changeLinePattern, 1)
+	if m.position != 1 {
+		t.Errorf("Expected to wrap around to line 1, got %d", m.position)
+	}
+	m.jumpTo(errorLinePattern, -1)
+	if m.position != 4 {
+		t.Errorf("Expected the error on line 4, got %d", m.position)
+	}
+
+	m.search("aws")
+	m.nextMatch(1)
+	if len(m.matches) != 2 || m.current != 1 || m.position != 2 {
+		t.Errorf("Expected to be on the second of two matches, got %d of %v", m.current, m.matches)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const SensitiveMask = "(sensitive)"
+
+// outputsParallelism is the number of projects whose outputs are read at the
+// same time by the outputs command.
+const outputsParallelism = 4
+
+type TerraformOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+type NamedOutput struct {
+	Name string
+	TerraformOutput
+}
+
+type OutputsModel struct {
+	path     string
+	name     string
+	outputs  []NamedOutput
+	revealed map[string]bool
+	status   string
+	cursor   int
+	offset   int
+	width    int
+	height   int
+}
+
+type OutputsMsg struct {
+	Path    string
+	Outputs []NamedOutput
+}
+
+func runOutputs(project *Project) tea.Cmd {
+	return func() tea.Msg {
+		// warnings on stderr must not end up in the JSON
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+		if err := runTerraform(project.Path, &stdout, &stderr, "output", "-json"); err != nil {
+			project.LastAction = Output
+			project.Output = withRunError(maskedOutput(project.Path, stdout.String()+stderr.String()), err)
+			return UpdatesFinishedMsg(fmt.Sprintf("Terraform Output: failed for %s", project.Name))
+		}
+
+		outputs, err := parseOutputs(stdout.String())
+		if err != nil {
+			return UpdatesFinishedMsg(fmt.Sprintf("Terraform Output: %s", err))
+		}
+		return OutputsMsg{Path: project.Path, Outputs: outputs}
+	}
+}
+
+func parseOutputs(output string) ([]NamedOutput, error) {
+	raw := map[string]TerraformOutput{}
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, err
+	}
+
+	outputs := []NamedOutput{}
+	for name, o := range raw {
+		outputs = append(outputs, NamedOutput{name, o})
+	}
+	slices.SortFunc(outputs, func(a, b NamedOutput) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return outputs, nil
+}
+
+// formatOutputType converts Terraform's JSON type encoding, such as
+// ["list","string"], into the HCL form list(string).
+func formatOutputType(raw json.RawMessage) string {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	return formatTypeValue(value)
+}
+
+func formatTypeValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) != 2 {
+			break
+		}
+		kind, _ := v[0].(string)
+		return fmt.Sprintf("%s(%s)", kind, formatTypeValue(v[1]))
+	case map[string]any:
+		attributes := []string{}
+		for _, k := range sortedKeys(v) {
+			attributes = append(attributes, fmt.Sprintf("%s=%s", k, formatTypeValue(v[k])))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(attributes, ", "))
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// formatOutputValue renders strings without quotes so they can be copied
+// as-is, and everything else as compact JSON.
+func formatOutputValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	compact := bytes.Buffer{}
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
+
+func createOutputsModel(project Project, outputs []NamedOutput, width int, height int) OutputsModel {
+	return OutputsModel{
+		path:     project.Path,
+		name:     project.Name,
+		outputs:  outputs,
+		revealed: map[string]bool{},
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *OutputsModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.offset = max(min(m.offset, m.cursor), m.cursor-m.listHeight()+1)
+}
+
+func (m *OutputsModel) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.outputs)-1))
+	m.status = ""
+
+	listHeight := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+}
+
+func (m *OutputsModel) listHeight() int {
+	return max(m.height-8, 1)
+}
+
+func (m *OutputsModel) toggleReveal() {
+	if m.cursor < len(m.outputs) {
+		name := m.outputs[m.cursor].Name
+		m.revealed[name] = !m.revealed[name]
+	}
+}
+
+func (m *OutputsModel) copyValue() {
+	if m.cursor >= len(m.outputs) {
+		return
+	}
+
+	output := m.outputs[m.cursor]
+	if err := clipboard.WriteAll(formatOutputValue(output.Value)); err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("Could not copy %s: %s", output.Name, err))
+		return
+	}
+	m.status = success.Render(fmt.Sprintf("Copied %s to clipboard", output.Name))
+}
+
+func (m *OutputsModel) outputsHeader() string {
+	title := outputTitle.Render(fmt.Sprintf("Outputs: %s", m.name))
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m *OutputsModel) renderOutputs() string {
+	body := strings.Builder{}
+	body.WriteString(m.outputsHeader())
+	body.WriteString("\n\n")
+
+	if len(m.outputs) == 0 {
+		body.WriteString(tableDate.Render(" No outputs") + "\n")
+	}
+
+	nameWidth := 0
+	for _, output := range m.outputs {
+		nameWidth = max(nameWidth, len(output.Name))
+	}
+
+	end := min(m.offset+m.listHeight(), len(m.outputs))
+	for i := m.offset; i < end; i++ {
+		output := m.outputs[i]
+		value := formatOutputValue(output.Value)
+		if output.Sensitive && !m.revealed[output.Name] {
+			value = warning.Render(SensitiveMask)
+		}
+
+		line := fmt.Sprintf(" %-*s  %s  %s", nameWidth, output.Name, tableDate.Render(formatOutputType(output.Type)), value)
+		line = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+		if i == m.cursor {
+			line = tableHighlighted.Render(line)
+		}
+		body.WriteString(line + "\n")
+	}
+
+	body.WriteString("\n " + m.status + "\n")
+	return body.String()
+}
+
+type ProjectOutputs struct {
+	Name    string                     `json:"name"`
+	Path    string                     `json:"path"`
+	Outputs map[string]TerraformOutput `json:"outputs,omitempty"`
+	Error   string                     `json:"error,omitempty"`
+}
+
+// runOutputsCommand implements `tarragon outputs`, which writes the outputs of
+// every project below the search path as a single JSON document.
+func runOutputsCommand(args []string, stdout io.Writer, cwd string) error {
+	flags := flag.NewFlagSet("outputs", flag.ContinueOnError)
+	flags.StringVar(&SearchPath, "path", cwd, "Path to search for Terraform projects")
+	flags.StringVar(&ConfigPath, "config", defaultConfigPath(), "Path to the config file")
+	showSensitive := flags.Bool("show-sensitive", false, "Include the values of sensitive outputs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if Settings, err = loadConfig(ConfigPath); err != nil {
+		return err
+	}
+
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+
+	results := make([]ProjectOutputs, len(projects))
+	var wg sync.WaitGroup
+	running := make(chan struct{}, outputsParallelism)
+	for i, project := range projects {
+		wg.Add(1)
+		running <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-running }()
+			results[i] = collectProjectOutputs(project, *showSensitive)
+		}()
+	}
+	wg.Wait()
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func collectProjectOutputs(project Project, showSensitive bool) ProjectOutputs {
+	result := ProjectOutputs{Name: project.Name, Path: project.Path}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	if err := runTerraform(project.Path, &stdout, &stderr, "output", "-json"); err != nil {
+		output := withRunError(maskedOutput(project.Path, stdout.String()+stderr.String()), err)
+		result.Error = strings.TrimSpace(removeANSIEscapeCodes(output))
+		return result
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &result.Outputs); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if !showSensitive {
+		for name, o := range result.Outputs {
+			if o.Sensitive {
+				o.Value = json.RawMessage(fmt.Sprintf("%q", SensitiveMask))
+				result.Outputs[name] = o
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseOutputs(t *testing.T) {
+	output := `{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "db_password": {"sensitive": true, "type": "string", "value": "hunter2"},
+  "subnets": {"sensitive": false, "type": ["list", "string"], "value": ["a", "b"]}
+}`
+	got, err := parseOutputs(output)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 outputs, got %d", len(got))
+	}
+	if got[0].Name != "db_password" || !got[0].Sensitive {
+		t.Errorf("Expected outputs sorted by name with sensitivity, got %+v", got[0])
+	}
+	if formatOutputType(got[1].Type) != "list(string)" {
+		t.Errorf("Expected list(string), got %s", formatOutputType(got[1].Type))
+	}
+	if formatOutputValue(got[1].Value) != `["a","b"]` {
+		t.Errorf("Expected compact list, got %s", formatOutputValue(got[1].Value))
+	}
+	if formatOutputValue(got[2].Value) != "vpc-123" {
+		t.Errorf("Expected unquoted string, got %s", formatOutputValue(got[2].Value))
+	}
+}
+
+func TestFormatOutputType(t *testing.T) {
+	cases := map[string]string{
+		`"number"`:                    "number",
+		`["map", ["list", "string"]]`: "map(list(string))",
+		`["object", {"port": "number", "host": "string"}]`: "object({host=string, port=number})",
+	}
+
+	for raw, want := range cases {
+		if got := formatOutputType(json.RawMessage(raw)); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestOutputsIgnoreWarnings(t *testing.T) {
+	script := `echo 'Warning: Deprecated attribute' >&2
+echo '{"vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"}}'`
+	project := Project{Name: "network", Path: fakeTerraform(t, script)}
+
+	t.Run("Outputs view", func(t *testing.T) {
+		msg, ok := runOutputs(&project)().(OutputsMsg)
+		if !ok || len(msg.Outputs) != 1 || msg.Outputs[0].Name != "vpc_id" {
+			t.Errorf("Expected vpc_id despite the warning, got %v", msg)
+		}
+	})
+
+	t.Run("Headless outputs", func(t *testing.T) {
+		result := collectProjectOutputs(project, false)
+		if result.Error != "" || len(result.Outputs) != 1 {
+			t.Errorf("Expected vpc_id despite the warning, got %+v", result)
+		}
+	})
+}
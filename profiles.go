@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/erikgeiser/promptkit/selection"
+)
+
+const (
+	// NoProfile is the picker entry that removes the profile of a project.
+	NoProfile  = "(none)"
+	SecretMask = "****"
+)
+
+// secretNamePattern matches variable names whose values are masked even if
+// the profile doesn't list them as secrets.
+var secretNamePattern = regexp.MustCompile(`(?i)(secret|token|password|passwd|private_key|access_key|client_key)`)
+
+// Profile is a named set of environment variables that Terraform runs with,
+// such as the AWS_PROFILE or TF_VAR_* values of an environment.
+type Profile struct {
+	Env map[string]string `json:"env"`
+
+	// EnvFile is a dotenv file, relative to the project, whose variables are
+	// added on top of Env. It is skipped if the project doesn't have it.
+	EnvFile string `json:"envFile"`
+
+	// Secrets are the names of variables whose values are never shown.
+	Secrets []string `json:"secrets"`
+}
+
+func validateProfiles(profiles map[string]Profile, projectProfiles map[string]string) error {
+	for _, pattern := range sortedKeys(projectProfiles) {
+		if _, ok := profiles[projectProfiles[pattern]]; !ok {
+			return fmt.Errorf("%s uses unknown profile %q", pattern, projectProfiles[pattern])
+		}
+	}
+	return nil
+}
+
+// profileStore remembers the profile of each project directory, so that every
+// command run in a project uses it.
+type profileStore struct {
+	mu       sync.Mutex
+	profiles map[string]string
+}
+
+var projectProfiles = &profileStore{profiles: map[string]string{}}
+
+func (s *profileStore) set(dir string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[dir] = name
+}
+
+func (s *profileStore) get(dir string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name, ok := s.profiles[dir]
+	return name, ok
+}
+
+// defaultProfile returns the profile the config assigns to a project, by
+// path or glob pattern relative to root.
+func defaultProfile(project Project, root string) string {
+	for _, pattern := range sortedKeys(Settings.ProjectProfiles) {
+		if matchesGroup(project, root, []string{pattern}) {
+			return Settings.ProjectProfiles[pattern]
+		}
+	}
+	return ""
+}
+
+// resolveProfiles sets the profile of every project: the one picked in the
+// UI, which is kept across refreshes, or the default from the config.
+func resolveProfiles(projects []Project) {
+	for i := range projects {
+		project := &projects[i]
+		name, ok := projectProfiles.get(project.Path)
+		if !ok {
+			name = defaultProfile(*project, SearchPath)
+			projectProfiles.set(project.Path, name)
+		}
+		project.Profile = name
+	}
+}
+
+// parseDotenv reads KEY=VALUE lines, ignoring comments and an optional
+// "export" prefix. Quotes around values are removed.
+func parseDotenv(data string) map[string]string {
+	env := map[string]string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[strings.TrimSpace(name)] = value
+	}
+	return env
+}
+
+// profileEnv returns the variables of the profile used in dir, as KEY=VALUE
+// pairs, together with the values that must be masked.
+func profileEnv(dir string) ([]string, []string, error) {
+	name, _ := projectProfiles.get(dir)
+	if name == "" {
+		return nil, nil, nil
+	}
+	profile, ok := Settings.Profiles[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	vars := map[string]string{}
+	for k, v := range profile.Env {
+		vars[k] = v
+	}
+	if profile.EnvFile != "" {
+		data, err := os.ReadFile(filepath.Join(dir, profile.EnvFile))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		for k, v := range parseDotenv(string(data)) {
+			vars[k] = v
+		}
+	}
+
+	env := []string{}
+	secrets := []string{}
+	for _, k := range sortedKeys(vars) {
+		env = append(env, k+"="+vars[k])
+		if vars[k] != "" && (slices.Contains(profile.Secrets, k) || secretNamePattern.MatchString(k)) {
+			secrets = append(secrets, vars[k])
+		}
+	}
+	return env, secrets, nil
+}
+
+// envNames returns the names of KEY=VALUE pairs, so that the variables a
+// command runs with can be logged without any of their values.
+func envNames(env []string) []string {
+	names := []string{}
+	for _, pair := range env {
+		name, _, _ := strings.Cut(pair, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// maskSecrets replaces the secret values in text, so they are never shown or
+// written to the log or reports.
+func maskSecrets(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, SecretMask)
+	}
+	return text
+}
+
+// maskedOutput masks the secrets of the profile used in dir in the output of
+// a command run there.
+func maskedOutput(dir string, output string) string {
+	_, secrets, _ := profileEnv(dir)
+	return maskSecrets(output, secrets)
+}
+
+func createProfilePicker(profiles map[string]Profile) *selection.Model[string] {
+	prompt := selection.New("Select profile:", append(sortedKeys(profiles), NoProfile))
+	prompt.Filter = nil
+	prompt.KeyMap.Up = mainKeys.Up.Keys()
+	prompt.KeyMap.Down = mainKeys.Down.Keys()
+	prompt.KeyMap.Select = mainKeys.Submit.Keys()
+	model := selection.NewModel(prompt)
+	model.Init()
+	return model
+}
+
+// applyProfile sets the profile of the target projects.
+func (m *MainModel) applyProfile(name string, paths []string) {
+	if name == NoProfile {
+		name = ""
+	}
+	for _, path := range paths {
+		projectProfiles.set(path, name)
+		if project := matchProjectInMemory(path, &m.projects); project != nil {
+			project.Profile = name
+		}
+	}
+	m.table.updateData(&m.projects)
+
+	if name == "" {
+		m.status = fmt.Sprintf("Removed the profile of %d projects", len(paths))
+		return
+	}
+	m.status = fmt.Sprintf("Using profile %s for %d projects", name, len(paths))
+}
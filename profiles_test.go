@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	env := parseDotenv("# comment\nAWS_PROFILE=prod\nexport TF_VAR_region=\"eu-west-1\"\n\nARM_CLIENT_SECRET='s3cr3t'\ninvalid\n")
+	want := map[string]string{"AWS_PROFILE": "prod", "TF_VAR_region": "eu-west-1", "ARM_CLIENT_SECRET": "s3cr3t"}
+	if len(env) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, env)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("Expected %s=%s, got %q", k, v, env[k])
+		}
+	}
+}
+
+func TestProfileEnv(t *testing.T) {
+	defer func(profiles map[string]Profile) { Settings.Profiles = profiles }(Settings.Profiles)
+	Settings.Profiles = map[string]Profile{
+		"prod": {
+			Env:     map[string]string{"AWS_PROFILE": "prod", "TF_VAR_db_password": "hunter2"},
+			EnvFile: ".env.prod",
+			Secrets: []string{"ARM_SUBSCRIPTION_ID"},
+		},
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env.prod"), []byte("ARM_SUBSCRIPTION_ID=1234-5678\nAWS_PROFILE=prod-admin\n"), 0o644)
+	projectProfiles.set(dir, "prod")
+
+	env, secrets, err := profileEnv(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"ARM_SUBSCRIPTION_ID=1234-5678", "AWS_PROFILE=prod-admin", "TF_VAR_db_password=hunter2"}
+	if !slices.Equal(env, want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+	if want := []string{"1234-5678", "hunter2"}; !slices.Equal(secrets, want) {
+		t.Errorf("Expected secrets %v, got %v", want, secrets)
+	}
+
+	masked := maskedOutput(dir, "subscription 1234-5678 with password hunter2 in prod-admin")
+	if strings.Contains(masked, "1234-5678") || strings.Contains(masked, "hunter2") || !strings.Contains(masked, "prod-admin") {
+		t.Errorf("Expected only the secrets to be masked, got %q", masked)
+	}
+
+	t.Run("Unknown profile", func(t *testing.T) {
+		projectProfiles.set(dir, "staging")
+		if _, _, err := profileEnv(dir); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
+
+func TestResolveProfiles(t *testing.T) {
+	defer func(path string, profiles map[string]string) {
+		SearchPath, Settings.ProjectProfiles = path, profiles
+	}(SearchPath, Settings.ProjectProfiles)
+	SearchPath = "/repo"
+	Settings.ProjectProfiles = map[string]string{"prod/*": "prod"}
+
+	projects := []Project{{Path: "/repo/prod/api"}, {Path: "/repo/dev/api"}, {Path: "/repo/prod/db"}}
+	projectProfiles.set("/repo/prod/db", "admin")
+	resolveProfiles(projects)
+
+	got := []string{projects[0].Profile, projects[1].Profile, projects[2].Profile}
+	if want := []string{"prod", "", "admin"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestDebugLogOmitsValues(t *testing.T) {
+	defer func(profiles map[string]Profile) { Settings.Profiles = profiles }(Settings.Profiles)
+	defer func(debug bool) { Debug = debug }(Debug)
+	defer log.SetOutput(os.Stderr)
+
+	Settings.Profiles = map[string]Profile{"prod": {Env: map[string]string{"API_KEY": "abc123", "TF_VAR_db_pass": "hunter2"}}}
+	dir := fakeTerraform(t, "exit 0")
+	projectProfiles.set(dir, "prod")
+
+	Debug = true
+	logged := bytes.Buffer{}
+	log.SetOutput(&logged)
+	executeTerraform(dir, "version")
+
+	if strings.Contains(logged.String(), "abc123") || strings.Contains(logged.String(), "hunter2") {
+		t.Errorf("Expected no values in the log, got %q", logged.String())
+	}
+	if !strings.Contains(logged.String(), "API_KEY TF_VAR_db_pass") {
+		t.Errorf("Expected the variable names in the log, got %q", logged.String())
+	}
+}
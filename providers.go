@@ -0,0 +1,502 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	LockFileName    = ".terraform.lock.hcl"
+	DefaultRegistry = "registry.terraform.io"
+)
+
+var (
+	lockBlockPattern        = regexp.MustCompile(`(?ms)^provider\s+"([^"]+)"\s*\{(.*?)^\}`)
+	lockVersionPattern      = regexp.MustCompile(`(?m)^\s*version\s*=\s*"([^"]*)"`)
+	lockConstraintsPattern  = regexp.MustCompile(`(?m)^\s*constraints\s*=\s*"([^"]*)"`)
+	lockHashPattern         = regexp.MustCompile(`"((?:h1|zh):[^"]+)"`)
+	requiredProvidersStart  = regexp.MustCompile(`required_providers\s*\{`)
+	requiredProviderObject  = regexp.MustCompile(`(?ms)^\s*([A-Za-z0-9_-]+)\s*=\s*\{(.*?)\}`)
+	requiredProviderString  = regexp.MustCompile(`(?m)^\s*([A-Za-z0-9_-]+)\s*=\s*"([^"]*)"`)
+	requiredProviderSource  = regexp.MustCompile(`(?m)^\s*source\s*=\s*"([^"]*)"`)
+	requiredProviderVersion = regexp.MustCompile(`(?m)^\s*version\s*=\s*"([^"]*)"`)
+)
+
+// Provider is a provider used by a project, as required in
+// required_providers and locked in .terraform.lock.hcl.
+type Provider struct {
+	Name        string
+	Source      string
+	Constraint  string
+	Version     string
+	Constraints string
+	Hashes      []string
+	Platforms   []string
+}
+
+// ProviderUsage lists the locked versions of a provider across projects.
+// Projects that require the provider without locking it are listed under an
+// empty version.
+type ProviderUsage struct {
+	Source   string
+	Versions map[string][]string
+}
+
+// differs reports whether projects lock different versions of the provider.
+func (u ProviderUsage) differs() bool {
+	return len(u.Versions) > 1
+}
+
+type UpdateProvidersMsg Project
+
+// normalizeProviderSource expands a provider source to its full address, so
+// that "hashicorp/aws" and "registry.terraform.io/hashicorp/aws" match.
+func normalizeProviderSource(source string, name string) string {
+	if source == "" {
+		source = "hashicorp/" + name
+	}
+	source = strings.ToLower(source)
+	if strings.Count(source, "/") == 1 {
+		source = DefaultRegistry + "/" + source
+	}
+	return source
+}
+
+func parseLockFile(data string) []Provider {
+	providers := []Provider{}
+	for _, block := range lockBlockPattern.FindAllStringSubmatch(data, -1) {
+		provider := Provider{Source: strings.ToLower(block[1])}
+		if match := lockVersionPattern.FindStringSubmatch(block[2]); match != nil {
+			provider.Version = match[1]
+		}
+		if match := lockConstraintsPattern.FindStringSubmatch(block[2]); match != nil {
+			provider.Constraints = match[1]
+		}
+		for _, hash := range lockHashPattern.FindAllStringSubmatch(block[2], -1) {
+			provider.Hashes = append(provider.Hashes, hash[1])
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// parseRequiredProviders reads the required_providers blocks of a .tf file.
+// Both the object form and the legacy form with only a version constraint
+// are understood.
+func parseRequiredProviders(data string) []Provider {
+	providers := []Provider{}
+	for _, start := range requiredProvidersStart.FindAllStringIndex(data, -1) {
+		body := data[start[1]:]
+		depth := 1
+		for i, r := range body {
+			if r == '{' {
+				depth++
+			} else if r == '}' {
+				depth--
+			}
+			if depth == 0 {
+				body = body[:i]
+				break
+			}
+		}
+
+		for _, object := range requiredProviderObject.FindAllStringSubmatch(body, -1) {
+			provider := Provider{Name: object[1]}
+			if match := requiredProviderSource.FindStringSubmatch(object[2]); match != nil {
+				provider.Source = match[1]
+			}
+			if match := requiredProviderVersion.FindStringSubmatch(object[2]); match != nil {
+				provider.Constraint = match[1]
+			}
+			provider.Source = normalizeProviderSource(provider.Source, provider.Name)
+			providers = append(providers, provider)
+		}
+
+		body = requiredProviderObject.ReplaceAllString(body, "")
+		for _, legacy := range requiredProviderString.FindAllStringSubmatch(body, -1) {
+			providers = append(providers, Provider{
+				Name:       legacy[1],
+				Source:     normalizeProviderSource("", legacy[1]),
+				Constraint: legacy[2],
+			})
+		}
+	}
+	return providers
+}
+
+// loadProviders reads the providers of the project in dir from its
+// required_providers, its lock file and the platforms installed in
+// .terraform/providers, sorted by source.
+func loadProviders(filesystem fs.FS, dir string) []Provider {
+	bySource := map[string]*Provider{}
+	providers := []*Provider{}
+	find := func(source string) *Provider {
+		if provider, ok := bySource[source]; ok {
+			return provider
+		}
+		provider := &Provider{Source: source}
+		bySource[source] = provider
+		providers = append(providers, provider)
+		return provider
+	}
+
+	files, _ := fs.Glob(filesystem, path.Join(dir, "*.tf"))
+	for _, file := range files {
+		data, err := fs.ReadFile(filesystem, file)
+		if err != nil {
+			continue
+		}
+		for _, required := range parseRequiredProviders(string(data)) {
+			provider := find(required.Source)
+			provider.Name = required.Name
+			provider.Constraint = required.Constraint
+		}
+	}
+
+	if data, err := fs.ReadFile(filesystem, path.Join(dir, LockFileName)); err == nil {
+		for _, locked := range parseLockFile(string(data)) {
+			provider := find(locked.Source)
+			provider.Version = locked.Version
+			provider.Constraints = locked.Constraints
+			provider.Hashes = locked.Hashes
+		}
+	}
+
+	result := []Provider{}
+	for _, provider := range providers {
+		if provider.Name == "" {
+			provider.Name = path.Base(provider.Source)
+		}
+		if provider.Version != "" {
+			dir := path.Join(dir, TerraformDir, "providers", provider.Source, provider.Version)
+			entries, _ := fs.ReadDir(filesystem, dir)
+			for _, entry := range entries {
+				if entry.IsDir() {
+					provider.Platforms = append(provider.Platforms, entry.Name())
+				}
+			}
+		}
+		result = append(result, *provider)
+	}
+	slices.SortFunc(result, func(a, b Provider) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+	return result
+}
+
+func loadProjectProviders(project Project) []Provider {
+	return loadProviders(os.DirFS(project.Path), ".")
+}
+
+// countHashes counts the h1 hashes, one per platform the provider is locked
+// for, and the zh hashes of the registry's release archives.
+func countHashes(hashes []string) (int, int) {
+	h1, zh := 0, 0
+	for _, hash := range hashes {
+		if strings.HasPrefix(hash, "h1:") {
+			h1++
+		} else {
+			zh++
+		}
+	}
+	return h1, zh
+}
+
+// buildProviderMatrix collects the locked provider versions of every
+// project. Providers whose versions differ between projects come first.
+func buildProviderMatrix(projects []Project, load func(Project) []Provider) []ProviderUsage {
+	usages := map[string]ProviderUsage{}
+	for _, project := range projects {
+		for _, provider := range load(project) {
+			usage, ok := usages[provider.Source]
+			if !ok {
+				usage = ProviderUsage{Source: provider.Source, Versions: map[string][]string{}}
+				usages[provider.Source] = usage
+			}
+			usage.Versions[provider.Version] = append(usage.Versions[provider.Version], project.Name)
+		}
+	}
+
+	matrix := []ProviderUsage{}
+	for _, source := range sortedKeys(usages) {
+		matrix = append(matrix, usages[source])
+	}
+	slices.SortStableFunc(matrix, func(a, b ProviderUsage) int {
+		if a.differs() == b.differs() {
+			return 0
+		}
+		if a.differs() {
+			return -1
+		}
+		return 1
+	})
+	return matrix
+}
+
+func runProvidersLock(project *Project, platforms []string) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"providers", "lock"}
+		for _, platform := range platforms {
+			args = append(args, "-platform="+platform)
+		}
+		output, err := executeTerraform(project.Path, args...)
+		project.LastAction = ProvidersLock
+		project.Output = withRunError(output, err)
+		return UpdateProvidersMsg(*project)
+	}
+}
+
+func runInitUpgrade(project *Project) tea.Cmd {
+	return func() tea.Msg {
+		output, err := executeTerraform(project.Path, "init", "-upgrade")
+		project.LastAction = InitUpgrade
+		project.Output = withRunError(output, err)
+		return UpdateProvidersMsg(*project)
+	}
+}
+
+type ProvidersModel struct {
+	path       string
+	name       string
+	providers  []Provider
+	matrix     []ProviderUsage
+	showMatrix bool
+	status     string
+	cursor     int
+	offset     int
+	width      int
+	height     int
+}
+
+func createProvidersModel(project Project, width int, height int) ProvidersModel {
+	return ProvidersModel{
+		path:      project.Path,
+		name:      project.Name,
+		providers: loadProjectProviders(project),
+		width:     width,
+		height:    height,
+	}
+}
+
+// reload reads the providers again after they were locked or upgraded.
+func (m *ProvidersModel) reload(projects []Project) {
+	if m.showMatrix {
+		m.matrix = buildProviderMatrix(projects, loadProjectProviders)
+	} else if project := matchProjectInMemory(m.path, &projects); project != nil {
+		m.providers = loadProjectProviders(*project)
+	}
+	m.moveCursor(0)
+}
+
+// toggleMatrix switches between the providers of the project and the
+// versions used across all projects.
+func (m *ProvidersModel) toggleMatrix(projects []Project) {
+	m.showMatrix = !m.showMatrix
+	m.cursor = 0
+	m.offset = 0
+	m.reload(projects)
+}
+
+func (m *ProvidersModel) rows() int {
+	if m.showMatrix {
+		return len(m.matrix)
+	}
+	return len(m.providers)
+}
+
+func (m *ProvidersModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.offset = max(min(m.offset, m.cursor), m.cursor-m.listHeight()+1)
+}
+
+func (m *ProvidersModel) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, m.rows()-1))
+	m.status = ""
+
+	listHeight := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+}
+
+// detailHeight is the number of lines below the list that show the
+// highlighted provider.
+const detailHeight = 8
+
+func (m *ProvidersModel) listHeight() int {
+	return max(m.height-8-detailHeight, 1)
+}
+
+func (m *ProvidersModel) providersHeader() string {
+	title := outputTitle.Render(fmt.Sprintf("Providers: %s", m.name))
+	if m.showMatrix {
+		title = outputTitle.Render("Providers: all projects")
+	}
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m *ProvidersModel) renderProviders() string {
+	body := strings.Builder{}
+	body.WriteString(m.providersHeader())
+	body.WriteString("\n\n")
+
+	if m.rows() == 0 {
+		body.WriteString(tableDate.Render(" No providers") + "\n")
+	}
+
+	lines := []string{}
+	if m.showMatrix {
+		lines = m.matrixLines()
+	} else {
+		lines = m.providerLines()
+	}
+
+	end := min(m.offset+m.listHeight(), len(lines))
+	for i := m.offset; i < end; i++ {
+		line := lipgloss.NewStyle().MaxWidth(m.width).Render(lines[i])
+		if i == m.cursor {
+			line = tableHighlighted.Render(line)
+		}
+		body.WriteString(line + "\n")
+	}
+
+	body.WriteString("\n")
+	body.WriteString(m.renderDetail())
+	body.WriteString("\n " + m.status + "\n")
+	return body.String()
+}
+
+func (m *ProvidersModel) providerLines() []string {
+	sourceWidth := 0
+	for _, provider := range m.providers {
+		sourceWidth = max(sourceWidth, len(provider.Source))
+	}
+
+	lines := []string{}
+	for _, provider := range m.providers {
+		version := provider.Version
+		if version == "" {
+			version = warning.Render("not locked")
+		}
+		lines = append(lines, fmt.Sprintf(" %-*s  %s  %s", sourceWidth, provider.Source, version, tableDate.Render(provider.Constraint)))
+	}
+	return lines
+}
+
+func (m *ProvidersModel) matrixLines() []string {
+	sourceWidth := 0
+	for _, usage := range m.matrix {
+		sourceWidth = max(sourceWidth, len(usage.Source))
+	}
+
+	lines := []string{}
+	for _, usage := range m.matrix {
+		versions := []string{}
+		for _, version := range sortedKeys(usage.Versions) {
+			label := version
+			if version == "" {
+				label = "not locked"
+			}
+			versions = append(versions, fmt.Sprintf("%s (%d)", label, len(usage.Versions[version])))
+		}
+
+		text := strings.Join(versions, "  ")
+		if usage.differs() {
+			text = warning.Render(text)
+		}
+		lines = append(lines, fmt.Sprintf(" %-*s  %s", sourceWidth, usage.Source, text))
+	}
+	return lines
+}
+
+// renderDetail shows the highlighted provider: its constraints, hashes and
+// platforms, or in the matrix which projects use each version.
+func (m *ProvidersModel) renderDetail() string {
+	detail := []string{}
+	if m.showMatrix && m.cursor < len(m.matrix) {
+		usage := m.matrix[m.cursor]
+		for _, version := range sortedKeys(usage.Versions) {
+			label := version
+			if version == "" {
+				label = "not locked"
+			}
+			detail = append(detail, fmt.Sprintf("%-10s %s", label, strings.Join(usage.Versions[version], ", ")))
+		}
+	} else if !m.showMatrix && m.cursor < len(m.providers) {
+		provider := m.providers[m.cursor]
+		h1, zh := countHashes(provider.Hashes)
+		detail = append(detail,
+			fmt.Sprintf("Required:  %s", provider.Constraint),
+			fmt.Sprintf("Locked:    %s (constraints %s)", provider.Version, provider.Constraints),
+			fmt.Sprintf("Hashes:    %d platform (h1), %d registry (zh)", h1, zh),
+			fmt.Sprintf("Installed: %s", strings.Join(provider.Platforms, ", ")),
+		)
+		for _, hash := range provider.Hashes {
+			if strings.HasPrefix(hash, "h1:") {
+				detail = append(detail, "  "+hash)
+			}
+		}
+	}
+
+	body := strings.Builder{}
+	for i, line := range detail {
+		if i == detailHeight {
+			break
+		}
+		body.WriteString(lipgloss.NewStyle().MaxWidth(m.width).Render(" "+tableDate.Render(line)) + "\n")
+	}
+	body.WriteString(strings.Repeat("\n", max(detailHeight-len(detail), 0)))
+	return body.String()
+}
+
+// providerTargets returns the projects to lock or upgrade: the selected
+// projects or, if none are selected, the project whose providers are shown.
+func (m *MainModel) providerTargets() []*Project {
+	targets := []*Project{}
+	for _, path := range m.table.visibleSelectedPaths() {
+		if project := matchProjectInMemory(path, &m.projects); project != nil {
+			targets = append(targets, project)
+		}
+	}
+	if len(targets) == 0 && !m.providers.showMatrix {
+		if project := matchProjectInMemory(m.providers.path, &m.projects); project != nil {
+			targets = append(targets, project)
+		}
+	}
+	return targets
+}
+
+// confirmProviders asks before running a provider command in the target
+// projects, since it rewrites their lock files.
+func (m *MainModel) confirmProviders(command TerraformCommand, run func(*Project) tea.Cmd) {
+	targets := m.providerTargets()
+	if len(targets) == 0 {
+		m.providers.status = "Select the projects to update first"
+		return
+	}
+
+	message := fmt.Sprintf("This will run `terraform %s` in %d projects", command, len(targets))
+	if command == ProvidersLock {
+		message = fmt.Sprintf("This will run `terraform %s` for %s in %d projects", command, strings.Join(Settings.LockPlatforms, ", "), len(targets))
+	}
+	m.confirm(message, func(m *MainModel) tea.Cmd {
+		m.message = fmt.Sprintf("Terraform %s: %d projects", command, len(targets))
+		batchArgs := []tea.Cmd{}
+		for _, project := range targets {
+			batchArgs = append(batchArgs, run(project))
+		}
+		return tea.Sequence(tea.Batch(batchArgs...), updatesFinished)
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+const testLockFile = `# This file is maintained automatically by "terraform init".
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:linux",
+    "h1:darwin",
+    "zh:0123",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+  hashes = [
+    "h1:linux",
+  ]
+}
+`
+
+const testRequiredProviders = `terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    cloudflare = {
+      source = "Cloudflare/cloudflare"
+    }
+    random = "~> 3.0"
+  }
+}
+`
+
+func TestParseLockFile(t *testing.T) {
+	providers := parseLockFile(testLockFile)
+	if len(providers) != 2 {
+		t.Fatalf("Expected 2 providers, got %v", providers)
+	}
+
+	aws := providers[0]
+	if aws.Source != "registry.terraform.io/hashicorp/aws" || aws.Version != "5.31.0" || aws.Constraints != "~> 5.0" {
+		t.Errorf("Unexpected provider: %+v", aws)
+	}
+	if h1, zh := countHashes(aws.Hashes); h1 != 2 || zh != 1 {
+		t.Errorf("Expected 2 h1 and 1 zh hashes, got %d and %d", h1, zh)
+	}
+}
+
+func TestParseRequiredProviders(t *testing.T) {
+	providers := parseRequiredProviders(testRequiredProviders)
+	got := []string{}
+	for _, provider := range providers {
+		got = append(got, provider.Name+" "+provider.Source+" "+provider.Constraint)
+	}
+
+	want := []string{
+		"aws registry.terraform.io/hashicorp/aws ~> 5.0",
+		"cloudflare registry.terraform.io/cloudflare/cloudflare ",
+		"random registry.terraform.io/hashicorp/random ~> 3.0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestLoadProviders(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"project/versions.tf":         {Data: []byte(testRequiredProviders)},
+		"project/.terraform.lock.hcl": {Data: []byte(testLockFile)},
+		"project/.terraform/providers/registry.terraform.io/hashicorp/aws/5.31.0/linux_amd64/terraform-provider-aws": {},
+	}
+
+	providers := loadProviders(filesystem, "project")
+	if len(providers) != 3 {
+		t.Fatalf("Expected 3 providers, got %v", providers)
+	}
+
+	aws := providers[1]
+	if aws.Constraint != "~> 5.0" || aws.Version != "5.31.0" || !slices.Equal(aws.Platforms, []string{"linux_amd64"}) {
+		t.Errorf("Unexpected provider: %+v", aws)
+	}
+	if cloudflare := providers[0]; cloudflare.Name != "cloudflare" || cloudflare.Version != "" {
+		t.Errorf("Expected cloudflare not to be locked, got %+v", cloudflare)
+	}
+}
+
+func TestBuildProviderMatrix(t *testing.T) {
+	locked := map[string][]Provider{
+		"api": {{Source: "aws", Version: "5.31.0"}, {Source: "random", Version: "3.6.0"}},
+		"db":  {{Source: "aws", Version: "5.20.0"}, {Source: "random", Version: "3.6.0"}},
+		"dns": {{Source: "aws", Version: "5.31.0"}},
+	}
+	projects := []Project{{Name: "api"}, {Name: "db"}, {Name: "dns"}}
+
+	matrix := buildProviderMatrix(projects, func(project Project) []Provider {
+		return locked[project.Name]
+	})
+	if len(matrix) != 2 {
+		t.Fatalf("Expected 2 providers, got %v", matrix)
+	}
+	if matrix[0].Source != "aws" || !matrix[0].differs() {
+		t.Errorf("Expected the differing aws versions first, got %v", matrix[0])
+	}
+	if got := matrix[0].Versions["5.31.0"]; !slices.Equal(got, []string{"api", "dns"}) {
+		t.Errorf("Expected api and dns to lock 5.31.0, got %v", got)
+	}
+	if matrix[1].differs() {
+		t.Errorf("Expected random to be locked to one version, got %v", matrix[1])
+	}
+}
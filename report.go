@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/erikgeiser/promptkit/selection"
+)
+
+type ReportFormat string
+
+const (
+	MarkdownReport ReportFormat = "markdown"
+	HTMLReport     ReportFormat = "html"
+	JUnitReport    ReportFormat = "junit"
+	SARIFReport    ReportFormat = "sarif"
+)
+
+var reportFormats = []ReportFormat{MarkdownReport, HTMLReport, JUnitReport, SARIFReport}
+
+// exportParallelism is the number of projects validated and planned at the
+// same time for a headless export.
+const exportParallelism = 4
+
+var reportExtensions = map[ReportFormat]string{
+	MarkdownReport: ".md",
+	HTMLReport:     ".html",
+	JUnitReport:    ".xml",
+	SARIFReport:    ".sarif",
+}
+
+// PlannedChange is a resource change listed in the output of a plan.
+type PlannedChange struct {
+	Address string
+	Action  string
+	Symbol  string
+}
+
+type ReportProject struct {
+	Project
+	Add       string
+	Change    string
+	Destroy   string
+	Summary   string
+	Resources []PlannedChange
+	Output    string
+}
+
+type Report struct {
+	Generated time.Time
+	Path      string
+	Projects  []ReportProject
+}
+
+type ReportExportedMsg struct {
+	Path string
+	Err  error
+}
+
+var changeSymbols = map[string]string{
+	"created":           "+",
+	"destroyed":         "-",
+	"updated in-place":  "~",
+	"replaced":          "-/+",
+	"read during apply": "<=",
+}
+
+// parsePlannedChanges lists the resources a plan will change, from the
+// "# address will be created" headers in the plan output.
+func parsePlannedChanges(output string) []PlannedChange {
+	changes := []PlannedChange{}
+	re := regexp.MustCompile(`(?m)^\s*# (\S+) (?:will|must) be (.+?)\s*$`)
+	for _, match := range re.FindAllStringSubmatch(removeANSIEscapeCodes(output), -1) {
+		action := strings.TrimSuffix(match[2], ",")
+		symbol, ok := changeSymbols[action]
+		if !ok {
+			symbol = "?"
+		}
+		changes = append(changes, PlannedChange{Address: match[1], Action: action, Symbol: symbol})
+	}
+	return changes
+}
+
+// planStatus returns the status shown instead of the plan changes if the plan
+// did not finish, or an empty string if it did.
+func planStatus(changes TerraformChanges) string {
+	symbols := currentTheme.Symbols
+	switch TerraformError(changes.Add) {
+	case PlanError:
+		return symbols.PlanError
+	case DriftError:
+		return symbols.PlanDrift
+	case LockError:
+		return symbols.PlanLocked
+	case TimeoutError:
+		return symbols.PlanTimeout
+	}
+	return ""
+}
+
+func newReport(projects []Project, path string, generated time.Time) Report {
+	report := Report{Generated: generated, Path: path}
+	for _, project := range projects {
+		entry := ReportProject{
+			Project: project,
+			Add:     fmt.Sprint(project.PlanChanges.Add),
+			Change:  fmt.Sprint(project.PlanChanges.Change),
+			Destroy: fmt.Sprint(project.PlanChanges.Destroy),
+			Output:  strings.Trim(removeANSIEscapeCodes(project.Output), "\n"),
+		}
+		entry.Summary = fmt.Sprintf("+%s ~%s -%s", entry.Add, entry.Change, entry.Destroy)
+		if status := planStatus(project.PlanChanges); status != "" {
+			entry.Add, entry.Change, entry.Destroy, entry.Summary = status, status, status, status
+		}
+		if project.LastAction == Plan {
+			entry.Resources = parsePlannedChanges(project.Output)
+		}
+		report.Projects = append(report.Projects, entry)
+	}
+	return report
+}
+
+func renderReport(projects []Project, format ReportFormat, generated time.Time) (string, error) {
+	report := newReport(projects, SearchPath, generated)
+	switch format {
+	case MarkdownReport:
+		return renderMarkdownReport(report), nil
+	case HTMLReport:
+		return renderHTMLReport(report)
+	case JUnitReport:
+		return renderJUnitReport(projects, generated)
+	case SARIFReport:
+		return renderSARIFReport(projects)
+	}
+	return "", fmt.Errorf("unknown report format %q", format)
+}
+
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// codeFence returns a fence that is longer than any backtick run in content.
+func codeFence(content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+func formatLastRun(project Project) string {
+	if project.LastRun.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", project.LastRun.Format("2006-01-02 15:04:05"),
+		project.LastDuration.Round(100*time.Millisecond))
+}
+
+func renderMarkdownReport(report Report) string {
+	md := strings.Builder{}
+	fmt.Fprintf(&md, "# Tarragon report\n\n")
+	fmt.Fprintf(&md, "Generated %s for `%s`.\n\n", report.Generated.Format("2006-01-02 15:04:05"), report.Path)
+
+	md.WriteString("| Project | Path | Valid | Add | Change | Destroy | Last Run |\n")
+	md.WriteString("|---|---|:-:|--:|--:|--:|---|\n")
+	for _, project := range report.Projects {
+		fmt.Fprintf(&md, "| %s | `%s` | %s | %s | %s | %s | %s |\n",
+			markdownCell(project.Name), markdownCell(project.Path), project.Valid,
+			project.Add, project.Change, project.Destroy, formatLastRun(project.Project))
+	}
+
+	for _, project := range report.Projects {
+		fmt.Fprintf(&md, "\n<details>\n<summary><b>%s</b> %s</summary>\n\n",
+			template.HTMLEscapeString(project.Name), template.HTMLEscapeString(project.Summary))
+		fmt.Fprintf(&md, "Path: `%s`\n\n", project.Path)
+
+		if len(project.Resources) > 0 {
+			for _, resource := range project.Resources {
+				fmt.Fprintf(&md, "- `%s` `%s` will be %s\n", resource.Symbol, resource.Address, resource.Action)
+			}
+			md.WriteString("\n")
+		}
+
+		if project.Output != "" {
+			fence := codeFence(project.Output)
+			fmt.Fprintf(&md, "Output of `terraform %s`:\n\n%stext\n%s\n%s\n\n", project.LastAction, fence, project.Output, fence)
+		}
+		md.WriteString("</details>\n")
+	}
+
+	return md.String()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lastRun": formatLastRun,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Tarragon report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #1f1f28; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #c8c8c8; padding: 0.3em 0.8em; text-align: left; }
+th { background: #f0f0f0; }
+code, pre { font-family: monospace; }
+pre { background: #f6f6f6; padding: 1em; overflow-x: auto; }
+details { margin-bottom: 1em; }
+summary { cursor: pointer; }
+.muted { color: #737c73; }
+</style>
+</head>
+<body>
+<h1>Tarragon report</h1>
+<p class="muted">Generated {{.Generated.Format "2006-01-02 15:04:05"}} for <code>{{.Path}}</code>.</p>
+<table>
+<tr><th>Project</th><th>Path</th><th>Valid</th><th>Add</th><th>Change</th><th>Destroy</th><th>Last Run</th></tr>
+{{- range .Projects}}
+<tr><td>{{.Name}}</td><td><code>{{.Path}}</code></td><td>{{.Valid}}</td><td>{{.Add}}</td><td>{{.Change}}</td><td>{{.Destroy}}</td><td>{{lastRun .Project}}</td></tr>
+{{- end}}
+</table>
+{{- range .Projects}}
+<details>
+<summary><b>{{.Name}}</b> {{.Summary}}</summary>
+<p>Path: <code>{{.Path}}</code></p>
+{{- if .Resources}}
+<ul>
+{{- range .Resources}}
+<li><code>{{.Symbol}}</code> <code>{{.Address}}</code> will be {{.Action}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Output}}
+<p>Output of <code>terraform {{.LastAction}}</code>:</p>
+<pre>{{.Output}}</pre>
+{{- end}}
+</details>
+{{- end}}
+</body>
+</html>
+`))
+
+func renderHTMLReport(report Report) (string, error) {
+	html := strings.Builder{}
+	if err := htmlReportTemplate.Execute(&html, report); err != nil {
+		return "", err
+	}
+	return html.String(), nil
+}
+
+// reportFormatFromPath guesses the report format from a file extension.
+func reportFormatFromPath(path string) ReportFormat {
+	ext := strings.ToLower(filepath.Ext(path))
+	for format, formatExt := range reportExtensions {
+		if ext == formatExt {
+			return format
+		}
+	}
+	if ext == ".htm" {
+		return HTMLReport
+	}
+	return MarkdownReport
+}
+
+func writeReport(path string, projects []Project, format ReportFormat) error {
+	report, err := renderReport(projects, format, time.Now())
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err = os.Stdout.WriteString(report)
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0o644)
+}
+
+// exportReport writes a report of the given projects to a timestamped file in
+// the working directory.
+func exportReport(projects []Project, format ReportFormat) tea.Cmd {
+	return func() tea.Msg {
+		name := fmt.Sprintf("tarragon-report-%s%s", time.Now().Format("20060102-150405"), reportExtensions[format])
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return ReportExportedMsg{Err: err}
+		}
+		return ReportExportedMsg{Path: path, Err: writeReport(path, projects, format)}
+	}
+}
+
+func createFormatPicker() *selection.Model[ReportFormat] {
+	prompt := selection.New("Export report as:", reportFormats)
+	prompt.Filter = nil
+	prompt.KeyMap.Up = mainKeys.Up.Keys()
+	prompt.KeyMap.Down = mainKeys.Down.Keys()
+	prompt.KeyMap.Select = mainKeys.Submit.Keys()
+	model := selection.NewModel(prompt)
+	model.Init()
+	return model
+}
+
+// runHeadlessExport validates every project below the search path, or only
+// those in group if it is not nil, plans them too if requested, and writes a
+// report without starting the UI.
+func runHeadlessExport(path string, format ReportFormat, plan bool, group []string) error {
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+	if group != nil {
+		projects = filterGroup(projects, SearchPath, group)
+	}
+
+	var wg sync.WaitGroup
+	running := make(chan struct{}, exportParallelism)
+	for i := range projects {
+		wg.Add(1)
+		running <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-running }()
+			runValidate(&projects[i])()
+			if plan {
+				runPlan(&projects[i])()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return writeReport(path, projects, format)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const reportPlanOutput = "Terraform will perform the following actions:\n\n" +
+	"  \x1b[1m# aws_instance.web\x1b[0m will be created\n" +
+	"  # aws_s3_bucket.logs will be updated in-place\n" +
+	"  # module.db.aws_db_instance.main must be replaced\n" +
+	"  # aws_iam_role.old will be destroyed\n\n" +
+	"Plan: 2 to add, 1 to change, 2 to destroy.\n"
+
+func TestParsePlannedChanges(t *testing.T) {
+	got := parsePlannedChanges(reportPlanOutput)
+	want := []PlannedChange{
+		{"aws_instance.web", "created", "+"},
+		{"aws_s3_bucket.logs", "updated in-place", "~"},
+		{"module.db.aws_db_instance.main", "replaced", "-/+"},
+		{"aws_iam_role.old", "destroyed", "-"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+var testTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRenderReport(t *testing.T) {
+	projects := []Project{
+		{
+			Name:        "web",
+			Path:        "/services/web",
+			Valid:       ConfigValid,
+			LastAction:  Plan,
+			Output:      reportPlanOutput,
+			PlanChanges: TerraformChanges{2, 1, 2},
+		},
+		{
+			Name:        "db <primary>",
+			Path:        "/services/db",
+			Valid:       ConfigInvalid,
+			LastAction:  Plan,
+			Output:      "\x1b[31mError:\x1b[0m Unsupported attribute",
+			PlanChanges: TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()},
+		},
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		report, err := renderReport(projects, MarkdownReport, testTime)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, want := range []string{
+			"| web | `/services/web` | ✓ | 2 | 1 | 2 | - |",
+			"<summary><b>web</b> +2 ~1 -2</summary>",
+			"- `+` `aws_instance.web` will be created",
+			"<summary><b>db &lt;primary&gt;</b> ✗ Error</summary>",
+			"Error: Unsupported attribute",
+		} {
+			if !strings.Contains(report, want) {
+				t.Errorf("Expected report to contain %q", want)
+			}
+		}
+		if strings.Contains(report, "\x1b") {
+			t.Error("Expected ANSI escape codes to be removed")
+		}
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		report, err := renderReport(projects, HTMLReport, testTime)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, want := range []string{
+			"<!DOCTYPE html>",
+			"<summary><b>db &lt;primary&gt;</b>",
+			"<li><code>-/&#43;</code> <code>module.db.aws_db_instance.main</code> will be replaced</li>",
+		} {
+			if !strings.Contains(report, want) {
+				t.Errorf("Expected report to contain %q", want)
+			}
+		}
+		if strings.Contains(report, "\x1b") {
+			t.Error("Expected ANSI escape codes to be removed")
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		if _, err := renderReport(projects, "pdf", testTime); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
+
+func TestReportFormatFromPath(t *testing.T) {
+	cases := map[string]ReportFormat{
+		"report.md":   MarkdownReport,
+		"report.HTML": HTMLReport,
+		"report.htm":  HTMLReport,
+		"-":           MarkdownReport,
+	}
+	for path, want := range cases {
+		if got := reportFormatFromPath(path); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, path, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExecFinishedMsg is sent when a program started from tarragon, such as the
+// editor or a shell, exits and the UI resumes.
+type ExecFinishedMsg struct {
+	Path string
+	Err  error
+}
+
+func userEditor() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	return os.Getenv("EDITOR")
+}
+
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// editorCommand builds the command that opens a file or directory in the
+// user's editor, at the given line if it is not zero. Editors that do not
+// understand +line are special-cased.
+func editorCommand(editor string, target string, line int) *exec.Cmd {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+
+	args := fields[1:]
+	switch filepath.Base(fields[0]) {
+	case "code", "code-insiders", "codium":
+		args = append(args, "--wait")
+		if line > 0 {
+			args = append(args, "--goto", fmt.Sprintf("%s:%d", target, line))
+		} else {
+			args = append(args, target)
+		}
+	case "subl", "zed":
+		args = append(args, "--wait")
+		if line > 0 {
+			target = fmt.Sprintf("%s:%d", target, line)
+		}
+		args = append(args, target)
+	default:
+		if line > 0 {
+			args = append(args, fmt.Sprintf("+%d", line))
+		}
+		args = append(args, target)
+	}
+	return exec.Command(fields[0], args...)
+}
+
+// projectEnv returns the environment variables exported to programs started
+// in a project, including the variables of its profile. The directory of the
+// project's Terraform binary is put first on the PATH, so terraform runs the
+// same version as in tarragon.
+func projectEnv(project Project) []string {
+	env, _, _ := profileEnv(project.Path)
+	env = append(env,
+		"TARRAGON_PROJECT="+project.Name,
+		"TARRAGON_PROJECT_PATH="+project.Path,
+		"TARRAGON_PROFILE="+project.Profile,
+		"TF_WORKSPACE="+project.Workspace,
+	)
+	if binary, err := terraformBinaries.get(project.Path); err == nil && binary != TerraformBinary {
+		env = append(env, "PATH="+filepath.Dir(binary)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+	return env
+}
+
+// projectProgram returns the program to run for name in a project: the
+// project's own Terraform binary for terraform, since programs are looked up
+// in tarragon's PATH rather than the one from projectEnv.
+func projectProgram(project Project, name string) string {
+	if name != TerraformBinary {
+		return name
+	}
+	if binary, err := terraformBinaries.get(project.Path); err == nil {
+		return binary
+	}
+	return name
+}
+
+// execInProject suspends the UI while cmd runs in the project directory.
+func execInProject(dir string, cmd *exec.Cmd) tea.Cmd {
+	cmd.Dir = dir
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return ExecFinishedMsg{Path: dir, Err: err}
+	})
+}
+
+func openEditor(project Project) tea.Cmd {
+	return execInProject(project.Path, editorCommand(userEditor(), project.Path, 0))
+}
+
+func openShell(project Project) tea.Cmd {
+	cmd := exec.Command(userShell())
+	cmd.Env = append(os.Environ(), projectEnv(project)...)
+	return execInProject(project.Path, cmd)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	cases := []struct {
+		editor string
+		want   []string
+	}{
+		{"", []string{"vi", "+12", "main.tf"}},
+		{"nvim", []string{"nvim", "+12", "main.tf"}},
+		{"emacs -nw", []string{"emacs", "-nw", "+12", "main.tf"}},
+		{"/usr/bin/code", []string{"/usr/bin/code", "--wait", "--goto", "main.tf:12"}},
+		{"subl", []string{"subl", "--wait", "main.tf:12"}},
+	}
+
+	for _, c := range cases {
+		if got := editorCommand(c.editor, "main.tf", 12).Args; !slices.Equal(got, c.want) {
+			t.Errorf("Expected %v, got %v", c.want, got)
+		}
+	}
+
+	t.Run("Directory", func(t *testing.T) {
+		want := []string{"code", "--wait", "/services/web"}
+		if got := editorCommand("code", "/services/web", 0).Args; !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+}
+
+func TestProjectEnv(t *testing.T) {
+	env := projectEnv(Project{Name: "web", Path: "/services/web", Workspace: "staging"})
+	if !slices.Contains(env, "TF_WORKSPACE=staging") || !slices.Contains(env, "TARRAGON_PROJECT_PATH=/services/web") {
+		t.Errorf("Unexpected environment: %v", env)
+	}
+}
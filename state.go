@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+)
+
+const (
+	columnAddress = "Address"
+	columnType    = "Type"
+	columnModule  = "Module"
+)
+
+type StateModel struct {
+	path      string
+	name      string
+	resources table.Model
+	resource  StateResource
+	nodes     []*AttributeNode
+	input     textinput.Model
+	operation StateOperation
+	cursor    int
+	offset    int
+	width     int
+	height    int
+}
+
+type StateDocument struct {
+	Values *StateValues `json:"values"`
+}
+
+type StateValues struct {
+	RootModule StateModule `json:"root_module"`
+}
+
+type StateModule struct {
+	Address      string          `json:"address"`
+	Resources    []StateResource `json:"resources"`
+	ChildModules []StateModule   `json:"child_modules"`
+}
+
+type StateResource struct {
+	Address         string         `json:"address"`
+	Mode            string         `json:"mode"`
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	ProviderName    string         `json:"provider_name"`
+	Values          map[string]any `json:"values"`
+	SensitiveValues map[string]any `json:"sensitive_values"`
+}
+
+type AttributeNode struct {
+	Key      string
+	Value    string
+	Children []*AttributeNode
+	Expanded bool
+	Depth    int
+}
+
+type (
+	StateListMsg struct {
+		Path      string
+		Resources []string
+	}
+	StateShowMsg struct {
+		Path     string
+		Resource StateResource
+	}
+)
+
+func runStateList(project *Project) tea.Cmd {
+	return func() tea.Msg {
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+		if err := runTerraform(project.Path, &stdout, &stderr, "state", "list"); err != nil {
+			project.LastAction = StateList
+			project.Output = withRunError(maskedOutput(project.Path, stdout.String()+stderr.String()), err)
+			return UpdatesFinishedMsg(fmt.Sprintf("Terraform State: failed to list %s", project.Name))
+		}
+		return StateListMsg{Path: project.Path, Resources: parseStateList(stdout.String())}
+	}
+}
+
+func runStateShow(project *Project, address string) tea.Cmd {
+	return func() tea.Msg {
+		// warnings on stderr must not end up in the JSON
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+		if err := runTerraform(project.Path, &stdout, &stderr, "show", "-json"); err != nil {
+			project.LastAction = Show
+			project.Output = withRunError(maskedOutput(project.Path, stdout.String()+stderr.String()), err)
+			return UpdatesFinishedMsg(fmt.Sprintf("Terraform State: failed to show %s", address))
+		}
+
+		resource, err := parseStateShow(stdout.String(), address)
+		if err != nil {
+			return UpdatesFinishedMsg(fmt.Sprintf("Terraform State: %s", err))
+		}
+		return StateShowMsg{Path: project.Path, Resource: resource}
+	}
+}
+
+func parseStateList(output string) []string {
+	resources := []string{}
+	for _, line := range strings.Split(removeANSIEscapeCodes(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			resources = append(resources, line)
+		}
+	}
+	return resources
+}
+
+func parseStateShow(output string, address string) (StateResource, error) {
+	var doc StateDocument
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		return StateResource{}, err
+	}
+	if doc.Values == nil {
+		return StateResource{}, fmt.Errorf("state is empty")
+	}
+
+	resource, ok := findStateResource(doc.Values.RootModule, address)
+	if !ok {
+		return StateResource{}, fmt.Errorf("%s not found in state", address)
+	}
+	return resource, nil
+}
+
+func findStateResource(module StateModule, address string) (StateResource, bool) {
+	for _, resource := range module.Resources {
+		if resource.Address == address {
+			return resource, true
+		}
+	}
+	for _, child := range module.ChildModules {
+		if resource, ok := findStateResource(child, address); ok {
+			return resource, true
+		}
+	}
+	return StateResource{}, false
+}
+
+// splitResourceAddress separates a resource address into its module path and
+// resource type, e.g. module.net.aws_subnet.private[0] -> (module.net, aws_subnet).
+func splitResourceAddress(address string) (module string, resourceType string) {
+	parts := strings.Split(address, ".")
+	i := 0
+	for i+1 < len(parts) && parts[i] == "module" {
+		i += 2
+	}
+	module = strings.Join(parts[:min(i, len(parts))], ".")
+
+	rest := parts[min(i, len(parts)):]
+	if len(rest) > 0 && rest[0] == "data" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		resourceType = rest[0]
+	}
+	return module, resourceType
+}
+
+func buildAttributeTree(values map[string]any, sensitive map[string]any) []*AttributeNode {
+	nodes := []*AttributeNode{}
+	for _, k := range sortedKeys(values) {
+		nodes = append(nodes, newAttributeNode(k, values[k], sensitive[k], 0))
+	}
+	return nodes
+}
+
+func newAttributeNode(key string, value any, sensitive any, depth int) *AttributeNode {
+	node := &AttributeNode{Key: key, Depth: depth}
+
+	if sensitive == true {
+		node.Value = "(sensitive)"
+		return node
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		childSensitive, _ := sensitive.(map[string]any)
+		for _, k := range sortedKeys(v) {
+			node.Children = append(node.Children, newAttributeNode(k, v[k], childSensitive[k], depth+1))
+		}
+		node.Value = fmt.Sprintf("{%d attributes}", len(v))
+	case []any:
+		childSensitive, _ := sensitive.([]any)
+		for i, item := range v {
+			var s any
+			if i < len(childSensitive) {
+				s = childSensitive[i]
+			}
+			node.Children = append(node.Children, newAttributeNode(fmt.Sprintf("[%d]", i), item, s, depth+1))
+		}
+		node.Value = fmt.Sprintf("[%d items]", len(v))
+	default:
+		encoded, _ := json.Marshal(v)
+		node.Value = string(encoded)
+	}
+	return node
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func flattenAttributeTree(nodes []*AttributeNode) []*AttributeNode {
+	visible := []*AttributeNode{}
+	for _, node := range nodes {
+		visible = append(visible, node)
+		if node.Expanded {
+			visible = append(visible, flattenAttributeTree(node.Children)...)
+		}
+	}
+	return visible
+}
+
+func createStateModel(project Project, resources []string, width int, height int) StateModel {
+	rows := []table.Row{}
+	for _, address := range resources {
+		module, resourceType := splitResourceAddress(address)
+		rows = append(rows, table.NewRow(table.RowData{
+			columnAddress: address,
+			columnType:    resourceType,
+			columnModule:  tablePath.Render(module),
+		}))
+	}
+
+	columns := []table.Column{
+		table.NewFlexColumn(columnAddress, "Address", 4).WithStyle(tableHeaderPrimary).WithFiltered(true),
+		table.NewFlexColumn(columnType, "Type", 2).WithFiltered(true),
+		table.NewFlexColumn(columnModule, "Module", 2),
+	}
+
+	return StateModel{
+		path:   project.Path,
+		name:   project.Name,
+		width:  width,
+		height: height,
+		resources: table.New(columns).
+			WithRows(rows).
+			HeaderStyle(tableHeader).
+			Filtered(true).
+			Focused(true).
+			BorderRounded().
+			WithKeyMap(mainKeys.tableKeyMap()).
+			WithTargetWidth(width).
+			WithPageSize(max(height-10, 1)).
+			WithMultiline(false).
+			WithBaseStyle(tableBase).
+			HighlightStyle(tableHighlighted).
+			WithStaticFooter(fmt.Sprintf("# Resources: %d", len(rows))),
+	}
+}
+
+func (m *StateModel) setSize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.resources = m.resources.WithTargetWidth(width).WithPageSize(max(height-10, 1))
+	m.offset = max(min(m.offset, m.cursor), m.cursor-m.treeHeight()+1)
+}
+
+func (m *StateModel) highlightedAddress() string {
+	address, _ := m.resources.HighlightedRow().Data[columnAddress].(string)
+	return address
+}
+
+// startPrompt asks for the extra argument of a state operation, such as the
+// destination address of a move.
+func (m *StateModel) startPrompt(operation StateOperation) tea.Cmd {
+	m.operation = operation
+	m.input = textinput.New()
+	m.input.Prompt = fmt.Sprintf(" %s: ", operation.prompt())
+	if operation.Command == StateMove {
+		m.input.SetValue(operation.Address)
+	}
+	return m.input.Focus()
+}
+
+// submitPrompt returns the operation with the entered argument. If the
+// operation needs another value, the returned command prompts for it and the
+// argument is left empty.
+func (m *StateModel) submitPrompt() (StateOperation, tea.Cmd) {
+	m.input.Blur()
+	operation := m.operation
+	value := strings.TrimSpace(m.input.Value())
+	if operation.Command == Import && operation.Address == "" {
+		if value == "" {
+			return operation, nil
+		}
+		operation.Address = value
+		return operation, m.startPrompt(operation)
+	}
+	operation.Argument = value
+	return operation, nil
+}
+
+func (m *StateModel) prompting() bool {
+	return m.input.Focused()
+}
+
+func (m *StateModel) showResource(resource StateResource) {
+	m.resource = resource
+	m.nodes = buildAttributeTree(resource.Values, resource.SensitiveValues)
+	m.cursor = 0
+	m.offset = 0
+}
+
+func (m *StateModel) closeResource() {
+	m.resource = StateResource{}
+	m.nodes = nil
+}
+
+func (m *StateModel) showingResource() bool {
+	return m.resource.Address != ""
+}
+
+func (m *StateModel) moveCursor(delta int) {
+	visible := flattenAttributeTree(m.nodes)
+	m.cursor = max(0, min(m.cursor+delta, len(visible)-1))
+
+	treeHeight := m.treeHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+treeHeight {
+		m.offset = m.cursor - treeHeight + 1
+	}
+}
+
+func (m *StateModel) toggleNode() {
+	visible := flattenAttributeTree(m.nodes)
+	if m.cursor < len(visible) && len(visible[m.cursor].Children) > 0 {
+		visible[m.cursor].Expanded = !visible[m.cursor].Expanded
+	}
+}
+
+func (m *StateModel) treeHeight() int {
+	return max(m.height-8, 1)
+}
+
+func (m *StateModel) stateHeader() string {
+	text := fmt.Sprintf("State: %s", m.name)
+	if m.showingResource() {
+		text = fmt.Sprintf("State (%s): %s", m.resource.Address, m.name)
+	}
+	title := outputTitle.Render(text)
+	line := strings.Repeat("-", max(0, m.width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m *StateModel) renderTree() string {
+	body := strings.Builder{}
+	body.WriteString(tableDate.Render(fmt.Sprintf(" %s  |  %s", m.resource.Type, m.resource.ProviderName)))
+	body.WriteString("\n\n")
+
+	visible := flattenAttributeTree(m.nodes)
+	end := min(m.offset+m.treeHeight(), len(visible))
+	for i := m.offset; i < end; i++ {
+		node := visible[i]
+		marker := "  "
+		if len(node.Children) > 0 {
+			marker = "▸ "
+			if node.Expanded {
+				marker = "▾ "
+			}
+		}
+
+		line := strings.Repeat("  ", node.Depth) + marker + node.Key
+		if len(node.Children) > 0 {
+			line += " " + tableDate.Render(node.Value)
+		} else {
+			line += " = " + node.Value
+		}
+
+		if i == m.cursor {
+			line = tableHighlighted.Render(line)
+		}
+		body.WriteString(" " + line + "\n")
+	}
+
+	if len(visible) == 0 {
+		body.WriteString(tableDate.Render(" No attributes") + "\n")
+	}
+	return body.String()
+}
+
+func (m *StateModel) renderState() string {
+	body := strings.Builder{}
+	body.WriteString(m.stateHeader())
+	body.WriteString("\n")
+
+	if m.showingResource() {
+		body.WriteString(m.renderTree())
+	} else {
+		body.WriteString(renderFilter(&m.resources))
+		body.WriteString("\n")
+		body.WriteString(m.resources.View())
+		body.WriteString("\n")
+		if m.prompting() {
+			body.WriteString(m.input.View())
+			body.WriteString("\n")
+		}
+	}
+	return body.String()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseStateList(t *testing.T) {
+	output := "aws_instance.web\nmodule.net.aws_subnet.private[0]\n\n"
+	got := parseStateList(output)
+
+	if len(got) != 2 || got[0] != "aws_instance.web" || got[1] != "module.net.aws_subnet.private[0]" {
+		t.Errorf("Unexpected resources: %v", got)
+	}
+}
+
+func TestSplitResourceAddress(t *testing.T) {
+	cases := []struct {
+		address      string
+		module       string
+		resourceType string
+	}{
+		{"aws_instance.web", "", "aws_instance"},
+		{"data.aws_ami.ubuntu", "", "aws_ami"},
+		{"module.net.aws_subnet.private[0]", "module.net", "aws_subnet"},
+		{`module.app["a"].module.db.data.aws_iam_policy.x`, `module.app["a"].module.db`, "aws_iam_policy"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.address, func(t *testing.T) {
+			module, resourceType := splitResourceAddress(c.address)
+			if module != c.module || resourceType != c.resourceType {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", c.module, c.resourceType, module, resourceType)
+			}
+		})
+	}
+}
+
+func TestParseStateShow(t *testing.T) {
+	output := `{"format_version":"1.0","values":{"root_module":{"resources":[],"child_modules":[{"address":"module.net","resources":[
+		{"address":"module.net.aws_vpc.main","mode":"managed","type":"aws_vpc","name":"main","provider_name":"registry.terraform.io/hashicorp/aws",
+		 "values":{"cidr_block":"10.0.0.0/16","tags":{"Name":"main"},"password":"hunter2"},"sensitive_values":{"password":true,"tags":{}}}
+	]}]}}}`
+
+	t.Run("Finds nested resource", func(t *testing.T) {
+		resource, err := parseStateShow(output, "module.net.aws_vpc.main")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resource.Type != "aws_vpc" {
+			t.Errorf("Expected aws_vpc, got %s", resource.Type)
+		}
+
+		nodes := buildAttributeTree(resource.Values, resource.SensitiveValues)
+		if len(nodes) != 3 {
+			t.Fatalf("Expected 3 attributes, got %d", len(nodes))
+		}
+		if nodes[0].Key != "cidr_block" || nodes[0].Value != `"10.0.0.0/16"` {
+			t.Errorf("Unexpected leaf: %+v", nodes[0])
+		}
+		if nodes[1].Value != "(sensitive)" {
+			t.Errorf("Expected sensitive value to be masked, got %s", nodes[1].Value)
+		}
+		if len(nodes[2].Children) != 1 {
+			t.Errorf("Expected tags to have one child, got %d", len(nodes[2].Children))
+		}
+	})
+
+	t.Run("Missing resource", func(t *testing.T) {
+		_, err := parseStateShow(output, "aws_vpc.other")
+		if err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
+
+func TestFlattenAttributeTree(t *testing.T) {
+	nodes := buildAttributeTree(map[string]any{
+		"a": map[string]any{"b": 1, "c": []any{1, 2}},
+		"d": "e",
+	}, nil)
+
+	if got := len(flattenAttributeTree(nodes)); got != 2 {
+		t.Errorf("Expected 2 visible nodes when collapsed, got %d", got)
+	}
+
+	nodes[0].Expanded = true
+	if got := len(flattenAttributeTree(nodes)); got != 4 {
+		t.Errorf("Expected 4 visible nodes when expanded, got %d", got)
+	}
+}
+
+func TestStateOperationArgs(t *testing.T) {
+	cases := []struct {
+		operation StateOperation
+		want      string
+	}{
+		{StateOperation{Command: StateMove, Address: "aws_vpc.a", Argument: "aws_vpc.b"}, "terraform state mv aws_vpc.a aws_vpc.b"},
+		{StateOperation{Command: StateRemove, Address: "aws_vpc.a"}, "terraform state rm aws_vpc.a"},
+		{StateOperation{Command: Import, Address: "aws_vpc.a", Argument: "vpc-123"}, "terraform import -input=false aws_vpc.a vpc-123"},
+		{StateOperation{Command: Taint, Address: "aws_vpc.a"}, "terraform taint aws_vpc.a"},
+		{StateOperation{Command: Untaint, Address: "aws_vpc.a"}, "terraform untaint aws_vpc.a"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.operation.Command.String(), func(t *testing.T) {
+			if got := c.operation.String(); got != c.want {
+				t.Errorf("Expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestImportPrompt(t *testing.T) {
+	m := StateModel{}
+	m.startPrompt(StateOperation{Command: Import})
+	m.input.SetValue("aws_s3_bucket.logs")
+
+	operation, cmd := m.submitPrompt()
+	if cmd == nil || operation.Argument != "" || !m.prompting() {
+		t.Fatal("Expected to be asked for the import ID next")
+	}
+	if m.operation.prompt() != "Import ID" {
+		t.Errorf("Expected the ID prompt, got %q", m.operation.prompt())
+	}
+
+	m.input.SetValue("my-logs-bucket")
+	operation, cmd = m.submitPrompt()
+	if cmd != nil || operation.String() != "terraform import -input=false aws_s3_bucket.logs my-logs-bucket" {
+		t.Errorf("Expected a complete import, got %q", operation)
+	}
+}
+
+func TestRunStateShowIgnoresWarnings(t *testing.T) {
+	script := `echo 'Warning: Deprecated attribute' >&2
+echo '{"values":{"root_module":{"resources":[{"address":"aws_vpc.main","type":"aws_vpc","name":"main","values":{}}]}}}'`
+	project := Project{Path: fakeTerraform(t, script)}
+
+	msg, ok := runStateShow(&project, "aws_vpc.main")().(StateShowMsg)
+	if !ok || msg.Resource.Address != "aws_vpc.main" {
+		t.Errorf("Expected aws_vpc.main despite the warning, got %v", msg)
+	}
+}
+
+func TestBackupStateIsIgnored(t *testing.T) {
+	project := Project{Path: fakeTerraform(t, `echo '{"version": 4}'`)}
+	backup, err := backupState(&project)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("Expected the backup to be written: %v", err)
+	}
+	ignore, err := os.ReadFile(filepath.Join(project.Path, TarragonDir, ".gitignore"))
+	if err != nil || strings.TrimSpace(string(ignore)) != "*" {
+		t.Errorf("Expected the backups to be ignored by git, got %q and %v", ignore, err)
+	}
+}
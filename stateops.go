@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	TarragonDir    = ".tarragon"
+	StateBackupDir = TarragonDir + "/backups"
+)
+
+type StateOperation struct {
+	Command  TerraformCommand
+	Address  string
+	Argument string
+}
+
+type StateOperationMsg struct {
+	Path    string
+	Message string
+}
+
+func (o StateOperation) args() []string {
+	switch o.Command {
+	case StateMove:
+		return []string{"state", "mv", o.Address, o.Argument}
+	case StateRemove:
+		return []string{"state", "rm", o.Address}
+	case Import:
+		return []string{"import", "-input=false", o.Address, o.Argument}
+	default:
+		return []string{o.Command.String(), o.Address}
+	}
+}
+
+// prompt returns the label for the next value an operation needs, or an
+// empty string if the resource address is enough. Import asks for the
+// address to import to first, since it can't be a resource already in state.
+func (o StateOperation) prompt() string {
+	switch {
+	case o.Command == StateMove:
+		return "Move to address"
+	case o.Command == Import && o.Address == "":
+		return "Import to address"
+	case o.Command == Import:
+		return "Import ID"
+	default:
+		return ""
+	}
+}
+
+func (o StateOperation) String() string {
+	return "terraform " + strings.Join(o.args(), " ")
+}
+
+func backupState(project *Project) (string, error) {
+	state := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	if err := runTerraform(project.Path, &state, &stderr, "state", "pull"); err != nil {
+		return "", fmt.Errorf("terraform state pull: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	dir := filepath.Join(project.Path, StateBackupDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	// backups hold the full state, secrets included, so keep them out of git
+	ignore := filepath.Join(project.Path, TarragonDir, ".gitignore")
+	if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(dir, fmt.Sprintf("terraform-%s.tfstate", time.Now().Format("20060102-150405.000")))
+	if err := os.WriteFile(file, state.Bytes(), 0o600); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+func runStateOperation(project *Project, operation StateOperation) tea.Cmd {
+	return func() tea.Msg {
+		output := strings.Builder{}
+		project.LastAction = operation.Command
+
+		backup, err := backupState(project)
+		if err != nil {
+			output.WriteString(fmt.Sprintf("State backup failed, %s was not run: %s\n", operation, err))
+			project.Output = output.String()
+			return StateOperationMsg{project.Path, fmt.Sprintf("Backup failed for %s", project.Name)}
+		}
+		output.WriteString(fmt.Sprintf("State backed up to %s\n\n$ %s\n", backup, operation))
+
+		result, err := executeTerraform(project.Path, operation.args()...)
+		output.WriteString(withRunError(result, err))
+		if err != nil {
+			project.Output = output.String()
+			return StateOperationMsg{project.Path, fmt.Sprintf("%s failed for %s", operation.Command, project.Name)}
+		}
+
+		plan, err := executeTerraformCommand(project.Path, Plan)
+		output.WriteString("\n$ terraform plan\n")
+		output.WriteString(withRunError(plan, err))
+
+		recordPlan(project, plan, err)
+		project.Output = output.String()
+		return StateOperationMsg{project.Path, fmt.Sprintf("%s finished for %s", operation.Command, project.Name)}
+	}
+}
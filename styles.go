@@ -3,36 +3,78 @@ package main
 import "github.com/charmbracelet/lipgloss"
 
 var (
+	currentTheme       Theme
+	tableBase          lipgloss.Style
+	tableHighlighted   lipgloss.Style
+	tableHeader        lipgloss.Style
+	tableHeaderPrimary lipgloss.Style
+	tablePath          lipgloss.Style
+	tableDate          lipgloss.Style
+	tableFilterTyping  lipgloss.Style
+	tableFilterSet     lipgloss.Style
+	success            lipgloss.Style
+	errorStyle         lipgloss.Style
+	warning            lipgloss.Style
+	outputTitle        lipgloss.Style
+	outputInfo         lipgloss.Style
+	searchMatch        lipgloss.Style
+	searchCurrent      lipgloss.Style
+)
+
+func init() {
+	applyTheme(darkTheme)
+}
+
+// color returns no color for an empty value so monochrome themes can leave
+// colors unset.
+func color(value string) lipgloss.TerminalColor {
+	if value == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(value)
+}
+
+func applyTheme(theme Theme) {
+	currentTheme = theme
+	monochrome := theme.Highlight == ""
+
 	tableBase = lipgloss.NewStyle().
-			BorderForeground(lipgloss.Color("#737c73")).
-			Foreground(lipgloss.Color("#DCD7BA")).
-			Align(lipgloss.Left)
+		BorderForeground(color(theme.Border)).
+		Foreground(color(theme.Text)).
+		Align(lipgloss.Left)
 	tableHighlighted = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#181616")).
-				Background(lipgloss.Color("#7a8382")).
-				Bold(true)
-	tableHeader        = lipgloss.NewStyle().Foreground(lipgloss.Color("#8ba4b0")).Bold(true)
-	tableHeaderPrimary = lipgloss.NewStyle().Foreground(lipgloss.Color("#8992a7"))
-	tablePath          = lipgloss.NewStyle().Italic(true).Faint(true)
-	tableDate          = lipgloss.NewStyle().Faint(true)
-	tableFilterTyping  = lipgloss.NewStyle().Foreground(lipgloss.Color("#b6927b"))
-	tableFilterSet     = lipgloss.NewStyle().Foreground(lipgloss.Color("#87a987"))
-	success            = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#87a987")).
-				Align(lipgloss.Center)
+		Foreground(color(theme.HighlightText)).
+		Background(color(theme.Highlight)).
+		Reverse(monochrome).
+		Bold(true)
+	tableHeader = lipgloss.NewStyle().Foreground(color(theme.Header)).Bold(true)
+	tableHeaderPrimary = lipgloss.NewStyle().Foreground(color(theme.HeaderPrimary))
+	tablePath = lipgloss.NewStyle().Foreground(color(theme.Muted)).Italic(true).Faint(true)
+	tableDate = lipgloss.NewStyle().Foreground(color(theme.Muted)).Faint(true)
+	tableFilterTyping = lipgloss.NewStyle().Foreground(color(theme.FilterTyping)).Underline(monochrome)
+	tableFilterSet = lipgloss.NewStyle().Foreground(color(theme.FilterSet)).Bold(monochrome)
+	success = lipgloss.NewStyle().
+		Foreground(color(theme.Success)).
+		Align(lipgloss.Center)
 	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#c4746e")).
-			Align(lipgloss.Center)
-	warning     = lipgloss.NewStyle().Foreground(lipgloss.Color("#b6927b"))
+		Foreground(color(theme.Error)).
+		Bold(monochrome).
+		Align(lipgloss.Center)
+	warning = lipgloss.NewStyle().Foreground(color(theme.Warning)).Underline(monochrome)
 	outputTitle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8ba4b0")).
-			Bold(true).
-			BorderStyle(lipgloss.RoundedBorder()).
-			Padding(0, 2)
+		Foreground(color(theme.Title)).
+		Bold(true).
+		BorderStyle(lipgloss.RoundedBorder()).
+		Padding(0, 2)
 	outputInfo = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8ba4b0")).
-			Bold(true).
-			BorderStyle(lipgloss.RoundedBorder()).
-			Padding(0, 2).
-			Faint(true)
-)
+		Foreground(color(theme.Title)).
+		Bold(true).
+		BorderStyle(lipgloss.RoundedBorder()).
+		Padding(0, 2).
+		Faint(true)
+	searchMatch = lipgloss.NewStyle().
+		Foreground(color(theme.HighlightText)).
+		Background(color(theme.FilterTyping)).
+		Underline(monochrome)
+	searchCurrent = tableHighlighted.Copy().Underline(true)
+}
@@ -4,12 +4,73 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/evertras/bubble-table/table"
 )
 
 type TableModel struct {
-	model table.Model
+	model      table.Model
+	columns    []string
+	sortColumn string
+	sortDesc   bool
+	compact    bool
+	filter     string
+	query      Query
+	queryErr   error
+	hidden     []string
+}
+
+// columnDefinition describes a column of the projects table. Columns that are
+// not marked compact are hidden when the table is narrower than compactWidth.
+type columnDefinition struct {
+	key     string
+	title   string
+	flex    int
+	compact bool
+}
+
+// sortSuffix marks the hidden row data that a column is sorted by, so that
+// numbers and dates sort by value rather than by their rendered text.
+const sortSuffix = ":sort"
+
+var defaultColumns = []string{"name", "path", "valid", "formatted", "add", "change", "destroy", "lastModified"}
+
+// compactWidth is the table width below which only the compact columns are
+// shown.
+const compactWidth = 100
+
+var columnDefinitions = map[string]columnDefinition{
+	"name":         {columnName, "Name", 2, true},
+	"path":         {columnPath, "Path", 4, false},
+	"valid":        {columnValid, "Valid", 1, true},
+	"add":          {columnAdd, "Add", 1, true},
+	"change":       {columnChange, "Change", 1, true},
+	"destroy":      {columnDestroy, "Destroy", 1, true},
+	"lastModified": {columnLastModified, "Last Modified", 3, false},
+	"workspace":    {columnWorkspace, "Workspace", 2, false},
+	"backend":      {columnBackend, "Backend", 1, false},
+	"version":      {columnVersion, "Terraform", 1, false},
+	"required":     {columnRequired, "Required", 2, false},
+	"resolved":     {columnResolved, "Resolved", 1, false},
+	"profile":      {columnProfile, "Profile", 1, false},
+	"hooks":        {columnHooks, "Hooks", 1, false},
+	"command":      {columnCommand, "Command", 2, false},
+	"formatted":    {columnFormatted, "Formatted", 1, false},
+	"lastRun":      {columnLastRun, "Last Run", 3, false},
+	"duration":     {columnDuration, "Duration", 1, false},
+}
+
+func validateColumns(columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("at least one column must be shown")
+	}
+	for _, column := range columns {
+		if _, ok := columnDefinitions[column]; !ok {
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return nil
 }
 
 func matchProjectInMemory(path string, projects *[]Project) *Project {
@@ -22,23 +83,89 @@ func matchProjectInMemory(path string, projects *[]Project) *Project {
 }
 
 func (m *TableModel) updateData(projects *[]Project) {
-	var selected []string
+	m.selectPaths(projects, m.selectedPaths())
+}
 
+// selectedPaths returns the paths of the selected projects, including those
+// hidden by the filter.
+func (m *TableModel) selectedPaths() []string {
+	return slices.Concat(m.hidden, m.visibleSelectedPaths())
+}
+
+// visibleSelectedPaths returns the paths of the selected projects that are
+// shown. Actions only run in these, never in projects hidden by the filter.
+func (m *TableModel) visibleSelectedPaths() []string {
+	selected := []string{}
 	for _, row := range m.model.SelectedRows() {
 		selected = append(selected, row.Data[columnProject].(Project).Path)
 	}
+	return selected
+}
 
-	m.model = m.model.WithRows(generateRowsFromProjects(projects, selected))
+// selectPaths replaces the current selection with the given project paths.
+// Only the projects that match the filter are shown, but the selection of the
+// others is kept for when the filter changes.
+func (m *TableModel) selectPaths(projects *[]Project, paths []string) {
+	visible := []Project{}
+	shown := []string{}
+	for _, project := range *projects {
+		if m.query.matches(project, time.Now()) {
+			visible = append(visible, project)
+			shown = append(shown, project.Path)
+		}
+	}
 
+	m.hidden = []string{}
+	for _, path := range paths {
+		if !slices.Contains(shown, path) {
+			m.hidden = append(m.hidden, path)
+		}
+	}
+
+	m.model = m.model.WithRows(generateRowsFromProjects(&visible, paths))
 	m.updateFooter()
 }
 
+// updateFilter applies the filter query once its text has changed. A query
+// that can't be parsed doesn't hide any projects.
+func (m *TableModel) updateFilter(projects *[]Project) {
+	filter := m.model.GetCurrentFilter()
+	if filter == m.filter {
+		return
+	}
+
+	m.filter = filter
+	m.query, m.queryErr = parseQuery(filter)
+	m.updateData(projects)
+}
+
+// visibleProjects returns the projects that pass the current filter, in
+// table order.
+func (m *TableModel) visibleProjects() []Project {
+	projects := []Project{}
+	for _, row := range m.model.GetVisibleRows() {
+		projects = append(projects, row.Data[columnProject].(Project))
+	}
+	return projects
+}
+
 func (m *TableModel) updateFooter() {
+	direction := "↑"
+	if m.sortDesc {
+		direction = "↓"
+	}
+
+	format := "Page %d/%d  |  # Projects: %d  |  Sort: %s %s"
+	if m.compact {
+		format = "%d/%d  |  %d  |  %s %s"
+	}
 	footerText := fmt.Sprintf(
-		"Page %d/%d  |  # Projects: %d",
+		format,
 		m.model.CurrentPage(),
 		m.model.MaxPages(),
 		m.model.TotalRows(),
+		columnDefinitions[m.sortColumn].title,
+		direction,
 	)
 
 	m.model = m.model.WithStaticFooter(footerText)
@@ -52,22 +179,46 @@ const (
 	columnDestroy      = "Destroy"
 	columnLastModified = "LastModified"
 	columnValid        = "Valid"
+	columnWorkspace    = "Workspace"
+	columnBackend      = "Backend"
+	columnVersion      = "Version"
+	columnRequired     = "Required"
+	columnResolved     = "Resolved"
+	columnProfile      = "Profile"
+	columnHooks        = "Hooks"
+	columnCommand      = "Command"
+	columnFormatted    = "Formatted"
+	columnLastRun      = "LastRun"
+	columnDuration     = "Duration"
 	columnProject      = "Project"
 )
 
-func (m *TableModel) renderTable() string {
+func renderFilter(model *table.Model) string {
 	filter := ""
-	if m.model.GetIsFilterInputFocused() {
-		filter = fmt.Sprintf(" Filter: %s_", m.model.GetCurrentFilter())
+	if model.GetIsFilterInputFocused() {
+		filter = fmt.Sprintf(" Filter: %s_", model.GetCurrentFilter())
 		filter = tableFilterTyping.Render(filter)
-	} else if m.model.GetCurrentFilter() != "" {
-		filter = fmt.Sprintf(" Filter: %s", m.model.GetCurrentFilter())
+	} else if model.GetCurrentFilter() != "" {
+		filter = fmt.Sprintf(" Filter: %s", model.GetCurrentFilter())
 		filter = tableFilterSet.Render(filter)
 	}
+	return filter
+}
+
+// renderQuery renders the filter, together with the reason it is ignored if
+// it can't be parsed.
+func (m *TableModel) renderQuery() string {
+	filter := renderFilter(&m.model)
+	if m.queryErr != nil {
+		filter += errorStyle.Render("  " + m.queryErr.Error())
+	}
+	return filter
+}
 
+func (m *TableModel) renderTable() string {
 	body := strings.Builder{}
 	body.WriteString("\n\n")
-	body.WriteString(filter)
+	body.WriteString(m.renderQuery())
 	body.WriteString("\n")
 	body.WriteString(m.model.View())
 	body.WriteString("\n\n")
@@ -76,45 +227,99 @@ func (m *TableModel) renderTable() string {
 }
 
 func createProjectsTable() TableModel {
-	columns := generateColumns()
+	columns := generateColumns(Settings.Columns)
 	rows := generateRowsFromProjects(&[]Project{}, []string{})
 
-	tableKeys := table.DefaultKeyMap()
-	tableKeys.RowDown.SetKeys(mainKeys.Down.Keys()...)
-	tableKeys.RowUp.SetKeys(mainKeys.Up.Keys()...)
-	tableKeys.Filter.SetKeys(mainKeys.Filter.Keys()...)
-
 	model := TableModel{
+		columns: Settings.Columns,
 		model: table.New(columns).
 			WithRows(rows).
 			HeaderStyle(tableHeader).
 			SelectableRows(true).
-			WithSelectedText("     ", "  •  ").
+			WithSelectedText("     ", fmt.Sprintf("  %s  ", currentTheme.Symbols.Selected)).
 			Filtered(true).
 			Focused(true).
 			BorderRounded().
-			SortByAsc(columnName).
-			WithKeyMap(tableKeys).
-			WithTargetWidth(WinSize.Width).
-			WithPageSize(WinSize.Height - 5).
+			WithKeyMap(mainKeys.tableKeyMap()).
 			WithMultiline(false).
 			WithBaseStyle(tableBase).
 			HighlightStyle(tableHighlighted),
 	}
 
-	model.updateFooter()
+	model.sortColumn = model.columns[0]
+	if slices.Contains(model.columns, "name") {
+		model.sortColumn = "name"
+	}
+	model.applySort()
 	return model
 }
 
-func generateColumns() []table.Column {
-	columns := []table.Column{
-		table.NewFlexColumn(columnName, "Name", 2).WithStyle(tableHeaderPrimary).WithFiltered(true),
-		table.NewFlexColumn(columnPath, "Path", 4).WithFiltered(true),
-		table.NewFlexColumn(columnValid, "Valid", 1),
-		table.NewFlexColumn(columnAdd, "Add", 1),
-		table.NewFlexColumn(columnChange, "Change", 1),
-		table.NewFlexColumn(columnDestroy, "Destroy", 1),
-		table.NewFlexColumn(columnLastModified, "Last Modified", 3),
+// tableChromeHeight is the number of lines around the rows of the table: the
+// spacing, filter, header, footer and borders.
+const tableChromeHeight = 11
+
+// resize fits the table into the given width and height, showing as many
+// rows per page as there is space for. Narrow tables switch to compact mode.
+func (m *TableModel) resize(width int, height int) {
+	if compact := width < compactWidth; compact != m.compact {
+		m.compact = compact
+		m.model = m.model.WithColumns(generateColumns(m.shownColumns()))
+	}
+	m.model = m.model.WithTargetWidth(width).WithPageSize(max(height-tableChromeHeight, 1))
+	m.updateFooter()
+}
+
+// shownColumns returns the configured columns, without the low-priority ones
+// in compact mode. At least the first column is always shown.
+func (m *TableModel) shownColumns() []string {
+	if !m.compact {
+		return m.columns
+	}
+
+	columns := []string{}
+	for _, column := range m.columns {
+		if columnDefinitions[column].compact {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return m.columns[:1]
+	}
+	return columns
+}
+
+// cycleSort moves the sort to the next visible column.
+func (m *TableModel) cycleSort() {
+	columns := m.shownColumns()
+	i := slices.Index(columns, m.sortColumn)
+	m.sortColumn = columns[(i+1)%len(columns)]
+	m.applySort()
+}
+
+func (m *TableModel) reverseSort() {
+	m.sortDesc = !m.sortDesc
+	m.applySort()
+}
+
+func (m *TableModel) applySort() {
+	key := columnDefinitions[m.sortColumn].key + sortSuffix
+	if m.sortDesc {
+		m.model = m.model.SortByDesc(key)
+	} else {
+		m.model = m.model.SortByAsc(key)
+	}
+	m.updateFooter()
+}
+
+func generateColumns(names []string) []table.Column {
+	columns := []table.Column{}
+	for _, name := range names {
+		definition := columnDefinitions[name]
+		column := table.NewFlexColumn(definition.key, definition.title, definition.flex)
+		if name == "name" {
+			column = column.WithStyle(tableHeaderPrimary)
+		}
+		columns = append(columns, column)
 	}
 
 	return columns
@@ -123,44 +328,61 @@ func generateColumns() []table.Column {
 func generateRowsFromProjects(projects *[]Project, selected []string) []table.Row {
 	rows := []table.Row{}
 	for i := range *projects {
-		// FIXME: fix this mess
-		addText := fmt.Sprint((*projects)[i].PlanChanges.Add)
-		changeText := fmt.Sprint((*projects)[i].PlanChanges.Change)
-		destroyText := fmt.Sprint((*projects)[i].PlanChanges.Destroy)
-		if addText == PlanError.String() || changeText == PlanError.String() ||
-			destroyText == PlanError.String() {
-			addText = errorStyle.Render("Error")
-			changeText = errorStyle.Render("Error")
-			destroyText = errorStyle.Render("Error")
-		} else if addText == DriftError.String() || changeText == DriftError.String() || destroyText == DriftError.String() {
-			addText = errorStyle.Render("Drift")
-			changeText = errorStyle.Render("Drift")
-			destroyText = errorStyle.Render("Drift")
-		}
+		project := (*projects)[i]
+		addText, changeText, destroyText := formatChanges(project.PlanChanges)
+		validText := formatValid(project.Valid)
 
-		var validText string
-		if (*projects)[i].Valid == ConfigValid {
-			validText = success.Render(ConfigValid)
-		} else if (*projects)[i].Valid == ConfigInvalid {
-			validText = errorStyle.Render(ConfigInvalid)
-		} else {
-			validText = ConfigUnknown
+		lastRun := "-"
+		duration := "-"
+		if !project.LastRun.IsZero() {
+			lastRun = project.LastRun.Format("2006-01-02 15:04:05")
+			duration = project.LastDuration.Round(100 * time.Millisecond).String()
 		}
 
 		row := table.NewRow(table.RowData{
-			columnName:    (*projects)[i].Name,
-			columnPath:    tablePath.Render((*projects)[i].Path),
+			columnName:    project.Name,
+			columnPath:    tablePath.Render(project.Path),
 			columnAdd:     addText,
 			columnChange:  changeText,
 			columnDestroy: destroyText,
 			columnValid:   validText,
 			columnLastModified: tableDate.Render(
-				(*projects)[i].LastModified.Format("2006-01-02 15:04:05"),
+				project.LastModified.Format("2006-01-02 15:04:05"),
 			),
-			columnProject: (*projects)[i],
+			columnWorkspace: project.Workspace,
+			columnBackend:   project.Backend,
+			columnVersion:   project.TerraformVersion,
+			columnRequired:  formatRequiredVersion(project),
+			columnResolved:  formatResolvedVersion(project),
+			columnProfile:   project.Profile,
+			columnHooks:     formatHooks(project.Hooks),
+			columnCommand:   formatCommand(project),
+			columnFormatted: formatFmt(project.Formatted),
+			columnLastRun:   tableDate.Render(lastRun),
+			columnDuration:  tableDate.Render(duration),
+			columnProject:   project,
+
+			columnName + sortSuffix:         strings.ToLower(project.Name),
+			columnPath + sortSuffix:         project.Path,
+			columnValid + sortSuffix:        project.Valid,
+			columnAdd + sortSuffix:          project.PlanChanges.Add,
+			columnChange + sortSuffix:       project.PlanChanges.Change,
+			columnDestroy + sortSuffix:      project.PlanChanges.Destroy,
+			columnLastModified + sortSuffix: project.LastModified.Unix(),
+			columnWorkspace + sortSuffix:    project.Workspace,
+			columnBackend + sortSuffix:      project.Backend,
+			columnVersion + sortSuffix:      project.TerraformVersion,
+			columnRequired + sortSuffix:     formatRequiredVersion(project),
+			columnResolved + sortSuffix:     project.ResolvedVersion,
+			columnProfile + sortSuffix:      project.Profile,
+			columnHooks + sortSuffix:        project.Hooks,
+			columnCommand + sortSuffix:      project.Command + project.CommandStatus,
+			columnFormatted + sortSuffix:    project.Formatted,
+			columnLastRun + sortSuffix:      project.LastRun.Unix(),
+			columnDuration + sortSuffix:     project.LastDuration,
 		})
 
-		if slices.Contains(selected, (*projects)[i].Path) {
+		if slices.Contains(selected, project.Path) {
 			row = row.Selected(true)
 		}
 
@@ -169,3 +391,44 @@ func generateRowsFromProjects(projects *[]Project, selected []string) []table.Ro
 
 	return rows
 }
+
+func formatChanges(changes TerraformChanges) (string, string, string) {
+	// FIXME: fix this mess
+	symbols := currentTheme.Symbols
+	addText := fmt.Sprint(changes.Add)
+	changeText := fmt.Sprint(changes.Change)
+	destroyText := fmt.Sprint(changes.Destroy)
+	if addText == PlanError.String() || changeText == PlanError.String() ||
+		destroyText == PlanError.String() {
+		addText = errorStyle.Render(symbols.PlanError)
+		changeText = errorStyle.Render(symbols.PlanError)
+		destroyText = errorStyle.Render(symbols.PlanError)
+	} else if addText == DriftError.String() || changeText == DriftError.String() || destroyText == DriftError.String() {
+		addText = errorStyle.Render(symbols.PlanDrift)
+		changeText = errorStyle.Render(symbols.PlanDrift)
+		destroyText = errorStyle.Render(symbols.PlanDrift)
+	} else if addText == LockError.String() || changeText == LockError.String() || destroyText == LockError.String() {
+		addText = warning.Render(symbols.PlanLocked)
+		changeText = warning.Render(symbols.PlanLocked)
+		destroyText = warning.Render(symbols.PlanLocked)
+	} else if addText == TimeoutError.String() || changeText == TimeoutError.String() || destroyText == TimeoutError.String() {
+		addText = warning.Render(symbols.PlanTimeout)
+		changeText = warning.Render(symbols.PlanTimeout)
+		destroyText = warning.Render(symbols.PlanTimeout)
+	}
+	return addText, changeText, destroyText
+}
+
+func formatValid(valid string) string {
+	symbols := currentTheme.Symbols
+	switch valid {
+	case ConfigValid:
+		return success.Render(symbols.Valid)
+	case ConfigInvalid:
+		return errorStyle.Render(symbols.Invalid)
+	case ConfigTimeout:
+		return warning.Render(symbols.Timeout)
+	default:
+		return symbols.Unknown
+	}
+}
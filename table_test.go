@@ -1,6 +1,7 @@
 package main
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -26,3 +27,95 @@ func TestMatchHighlightedProject(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateColumns(t *testing.T) {
+	if err := validateColumns(defaultColumns); err != nil {
+		t.Errorf("Unexpected error for default columns: %v", err)
+	}
+	if err := validateColumns([]string{"name", "cost"}); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+	if err := validateColumns([]string{}); err == nil {
+		t.Error("Expected an error for no columns")
+	}
+}
+
+func TestSortProjects(t *testing.T) {
+	projects := []Project{
+		{Name: "b", Path: "b", PlanChanges: TerraformChanges{0, 0, 3}},
+		{Name: "a", Path: "a", PlanChanges: TerraformChanges{0, 0, 12}},
+		{Name: "c", Path: "c", PlanChanges: TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}},
+	}
+
+	m := createProjectsTable()
+	m.updateData(&projects)
+	assertRowOrder(t, m, "a", "b", "c")
+
+	for m.sortColumn != "destroy" {
+		m.cycleSort()
+	}
+	m.reverseSort()
+	assertRowOrder(t, m, "a", "b", "c")
+
+	m.reverseSort()
+	assertRowOrder(t, m, "c", "b", "a")
+}
+
+func assertRowOrder(t *testing.T, m TableModel, names ...string) {
+	t.Helper()
+	rows := m.model.GetVisibleRows()
+	for i, name := range names {
+		if got := rows[i].Data[columnProject].(Project).Name; got != name {
+			t.Errorf("Expected %s at row %d, got %s", name, i, got)
+		}
+	}
+}
+
+func TestCompactColumns(t *testing.T) {
+	m := createProjectsTable()
+
+	m.resize(80, 30)
+	if want := []string{"name", "valid", "add", "change", "destroy"}; !slices.Equal(m.shownColumns(), want) {
+		t.Errorf("Expected %v in compact mode, got %v", want, m.shownColumns())
+	}
+
+	m.resize(150, 30)
+	if !slices.Equal(m.shownColumns(), defaultColumns) {
+		t.Errorf("Expected all columns when wide, got %v", m.shownColumns())
+	}
+
+	t.Run("Keeps the first column", func(t *testing.T) {
+		m := TableModel{columns: []string{"path", "lastRun"}, compact: true}
+		if want := []string{"path"}; !slices.Equal(m.shownColumns(), want) {
+			t.Errorf("Expected %v, got %v", want, m.shownColumns())
+		}
+	})
+}
+
+func TestFilterProjects(t *testing.T) {
+	projects := []Project{
+		{Name: "a", Path: "a", PlanChanges: TerraformChanges{0, 0, 3}},
+		{Name: "b", Path: "b", PlanChanges: TerraformChanges{0, 0, 0}},
+	}
+
+	m := createProjectsTable()
+	m.selectPaths(&projects, []string{"a", "b"})
+	m.model = m.model.WithFilterInputValue("destroy>0")
+	m.updateFilter(&projects)
+
+	if rows := m.model.GetVisibleRows(); len(rows) != 1 || rows[0].Data[columnProject].(Project).Name != "a" {
+		t.Errorf("Expected only a to be shown, got %d rows", len(rows))
+	}
+	if selected := m.selectedPaths(); !slices.Contains(selected, "b") {
+		t.Errorf("Expected the selection of hidden projects to be kept, got %v", selected)
+	}
+	if targets := m.visibleSelectedPaths(); !slices.Equal(targets, []string{"a"}) {
+		t.Errorf("Expected only the shown selection to be acted on, got %v", targets)
+	}
+
+	m.model = m.model.WithFilterInputValue("destroy>")
+	m.updateFilter(&projects)
+	if m.queryErr == nil || len(m.model.GetVisibleRows()) != 2 {
+		t.Error("Expected an invalid query to show every project with an error")
+	}
+}
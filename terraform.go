@@ -4,10 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,11 +16,26 @@ const (
 	Plan          TerraformCommand = "plan"
 	Validate      TerraformCommand = "validate"
 	Apply         TerraformCommand = "apply"
+	StateList     TerraformCommand = "state list"
+	Show          TerraformCommand = "show"
+	StateMove     TerraformCommand = "state mv"
+	StateRemove   TerraformCommand = "state rm"
+	Import        TerraformCommand = "import"
+	Taint         TerraformCommand = "taint"
+	Untaint       TerraformCommand = "untaint"
+	Output        TerraformCommand = "output"
+	ForceUnlock   TerraformCommand = "force-unlock"
+	ProvidersLock TerraformCommand = "providers lock"
+	InitUpgrade   TerraformCommand = "init -upgrade"
+	Fmt           TerraformCommand = "fmt"
 	PlanError     TerraformError   = -1
 	DriftError    TerraformError   = -2
+	LockError     TerraformError   = -3
+	TimeoutError  TerraformError   = -4
 	ConfigValid   string           = "✓"
 	ConfigInvalid string           = "✗"
 	ConfigUnknown string           = "?"
+	ConfigTimeout string           = "⧗"
 )
 
 type TerraformCommand string
@@ -56,6 +71,22 @@ type ChangeSummary struct {
 	Remove int `json:"remove"`
 }
 
+// LockInfo describes the holder of a state lock, as reported by Terraform
+// when it fails to acquire the lock.
+type LockInfo struct {
+	ID        string
+	Path      string
+	Operation string
+	Who       string
+	Version   string
+	Created   string
+	Info      string
+}
+
+func (l LockInfo) String() string {
+	return fmt.Sprintf("lock %s held by %s since %s (%s)", l.ID, l.Who, l.Created, l.Operation)
+}
+
 type RegexMatchError struct {
 	Message string
 }
@@ -70,49 +101,151 @@ func updatesFinished() tea.Msg {
 
 func runValidate(project *Project) tea.Cmd {
 	return func() tea.Msg {
-		output := executeTerraformCommand(project.Path, Validate)
-		if strings.Contains(output, "The configuration is valid") {
+		start := time.Now()
+		hooks, pre, err := startHooks(project, Validate)
+		result, output := ValidateResult{}, ""
+		if err == nil {
+			result, output, err = executeValidate(project.Path)
+		}
+		recordRun(project, start)
+		project.Diagnostics = result.Diagnostics
+		if _, timedOut := err.(RunTimeoutError); timedOut {
+			project.Valid = ConfigTimeout
+			output = withRunError(output, err)
+		} else if err != nil {
+			project.Valid = ConfigInvalid
+			output = withRunError(output, err)
+		} else if result.Valid {
 			project.Valid = ConfigValid
 		} else {
 			project.Valid = ConfigInvalid
 		}
 		project.LastAction = Validate
-		project.Output = output
+		project.Output = pre + output + hooks.finish(err)
 		return UpdateValidateMsg(*project)
 	}
 }
 
 func runPlan(project *Project) tea.Cmd {
 	return func() tea.Msg {
-		output := executeTerraformCommand(project.Path, Plan)
-		parsedPlan := parsePlanOutput(output)
-		project.PlanChanges = parsedPlan
+		start := time.Now()
+		hooks, pre, err := startHooks(project, Plan)
+		output, failed := "", err
+		if err == nil {
+			output, failed = runTerraformCommand(project.Path, Plan)
+			err = stopError(failed)
+		}
+		recordRun(project, start)
+		recordPlan(project, output, err)
 		project.LastAction = Plan
-		project.Output = output
+		project.Output = pre + withRunError(output, err) + hooks.finish(failed)
 		return UpdatePlanMsg(*project)
 	}
 }
 
 func runApply(project *Project) tea.Cmd {
 	return func() tea.Msg {
-		output := executeTerraformCommand(project.Path, Apply)
+		start := time.Now()
+		hooks, pre, err := startHooks(project, Apply)
+		if err != nil {
+			// nothing was applied, so the last plan still holds
+			project.LastAction = Apply
+			project.Output = pre + withRunError("", err) + hooks.finish(err)
+			return UpdateApplyMsg(*project)
+		}
+
+		project.Lock = LockInfo{}
+		output, failed := runTerraformCommand(project.Path, Apply)
+		err = stopError(failed)
+		recordRun(project, start)
 		project.PlanChanges = TerraformChanges{0, 0, 0}
+		if _, mismatch := err.(VersionMismatchError); mismatch {
+			project.PlanChanges = TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}
+		} else if err != nil {
+			project.PlanChanges = TerraformChanges{TimeoutError.Value(), TimeoutError.Value(), TimeoutError.Value()}
+		} else if lock, locked := parseLockInfo(output); locked {
+			project.PlanChanges = TerraformChanges{LockError.Value(), LockError.Value(), LockError.Value()}
+			project.Lock = lock
+		}
 		project.LastAction = Apply
-		project.Output = output
+		project.Output = pre + withRunError(output, err) + hooks.finish(failed)
 		return UpdateApplyMsg(*project)
 	}
 }
 
-func executeTerraformCommand(dir string, command TerraformCommand) string {
+func runForceUnlock(project *Project) tea.Cmd {
+	return func() tea.Msg {
+		output, err := executeTerraform(project.Path, ForceUnlock.String(), "-force", project.Lock.ID)
+		project.LastAction = ForceUnlock
+		if err != nil {
+			project.Output = withRunError(output, err)
+			return UpdatePlanMsg(*project)
+		}
+
+		plan, err := executeTerraformCommand(project.Path, Plan)
+		recordPlan(project, plan, err)
+		project.Output = output + "\n$ terraform plan\n" + withRunError(plan, err)
+		return UpdatePlanMsg(*project)
+	}
+}
+
+func recordRun(project *Project, start time.Time) {
+	project.LastRun = start
+	project.LastDuration = time.Since(start)
+}
+
+// recordPlan updates the project's plan changes and lock from a plan run.
+func recordPlan(project *Project, output string, err error) {
+	project.Lock, _ = parseLockInfo(output)
+	switch err.(type) {
+	case RunTimeoutError:
+		project.PlanChanges = TerraformChanges{TimeoutError.Value(), TimeoutError.Value(), TimeoutError.Value()}
+		return
+	case VersionMismatchError, HookError:
+		project.PlanChanges = TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}
+		return
+	}
+	project.PlanChanges = parsePlanOutput(output)
+}
+
+// withRunError appends the reason a command was stopped, or never started, to
+// its partial output.
+func withRunError(output string, err error) string {
+	switch err.(type) {
+	case RunTimeoutError, VersionMismatchError, HookError:
+		return strings.TrimLeft(fmt.Sprintf("%s\n\n%s", output, err), "\n")
+	}
+	return output
+}
+
+// executeTerraformCommand runs one of the main Terraform actions. Failures of
+// the command itself are reported through its output, so the returned error
+// is only set if the command had to be stopped or could not be started with
+// the required Terraform version.
+func executeTerraformCommand(dir string, command TerraformCommand) (string, error) {
+	out, err := runTerraformCommand(dir, command)
+	return out, stopError(err)
+}
+
+// runTerraformCommand is executeTerraformCommand without hiding failures of
+// the command itself, which are returned as well, e.g. for hooks that must
+// only run if the command succeeded.
+func runTerraformCommand(dir string, command TerraformCommand) (string, error) {
 	flags := []string{command.String()}
 	if command == Apply {
 		flags = append(flags, "-auto-approve")
 	}
-	cmd := exec.Command("terraform", flags...)
-	cmd.Dir = dir
+	return executeTerraform(dir, flags...)
+}
 
-	out, _ := cmd.CombinedOutput()
-	return string(out)
+// stopError keeps only the errors that stopped a command, or kept it from
+// starting with the required Terraform version.
+func stopError(err error) error {
+	switch err.(type) {
+	case RunTimeoutError, VersionMismatchError:
+		return err
+	}
+	return nil
 }
 
 func parsePlanOutputJSON(output string) TerraformChanges {
@@ -145,6 +278,8 @@ func parsePlanOutputJSON(output string) TerraformChanges {
 
 func parsePlanOutput(output string) TerraformChanges {
 	switch {
+	case strings.Contains(output, "Error acquiring the state lock"):
+		return TerraformChanges{LockError.Value(), LockError.Value(), LockError.Value()}
 	case strings.Contains(output, "Error:"):
 		return TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}
 	case strings.Contains(output, "Objects have changed outside of Terraform"):
@@ -154,15 +289,41 @@ func parsePlanOutput(output string) TerraformChanges {
 	default:
 		changes, err := regexMatchChanges(output)
 		if err != nil {
+			// e.g. a plan that was cut short without an error message
 			if Debug {
 				log.Printf("Error parsing plan output: %s", err)
 			}
-			panic(err)
+			return TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}
 		}
 		return changes
 	}
 }
 
+func parseLockInfo(output string) (LockInfo, bool) {
+	output = removeANSIEscapeCodes(output)
+	if !strings.Contains(output, "Error acquiring the state lock") {
+		return LockInfo{}, false
+	}
+
+	fields := map[string]string{}
+	re := regexp.MustCompile(`(?m)^[│\s]*(ID|Path|Operation|Who|Version|Created|Info):[ \t]*(.*?)\s*$`)
+	for _, match := range re.FindAllStringSubmatch(output, -1) {
+		if _, seen := fields[match[1]]; !seen {
+			fields[match[1]] = match[2]
+		}
+	}
+
+	return LockInfo{
+		ID:        fields["ID"],
+		Path:      fields["Path"],
+		Operation: fields["Operation"],
+		Who:       fields["Who"],
+		Version:   fields["Version"],
+		Created:   fields["Created"],
+		Info:      fields["Info"],
+	}, true
+}
+
 func regexMatchChanges(output string) (TerraformChanges, error) {
 	output = removeANSIEscapeCodes(output)
 	re := regexp.MustCompile(`Plan: (\d+) to add, (\d+) to change, (\d+) to destroy.`)
@@ -48,6 +48,14 @@ func TestPlanParse(t *testing.T) {
 		assertMatchingChanges(t, got, want)
 	})
 
+	t.Run("Unparseable output", func(t *testing.T) {
+		output := "Terraform used the selected providers to generate the following execution plan."
+		got := parsePlanOutput(output)
+		want := TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}
+
+		assertMatchingChanges(t, got, want)
+	})
+
 	t.Run("Outside changes", func(t *testing.T) {
 		output := "Objects have changed outside of Terraform"
 		got := parsePlanOutput(output)
@@ -57,6 +65,57 @@ func TestPlanParse(t *testing.T) {
 	})
 }
 
+func TestLockParse(t *testing.T) {
+	output := `╷
+│ Error: Error acquiring the state lock
+│ 
+│ Error message: ConditionalCheckFailedException: The conditional request failed
+│ Lock Info:
+│   ID:        5f2b1c3e-8f3a-4b7e-9d2a-1c0b6e7f8a9d
+│   Path:      my-bucket/terraform.tfstate
+│   Operation: OperationTypeApply
+│   Who:       alice@laptop
+│   Version:   1.5.7
+│   Created:   2024-03-01 10:15:00.123456 +0000 UTC
+│   Info:      
+│ 
+│ Terraform acquires a state lock to protect the state from being written
+│ by multiple users at the same time.
+╵`
+
+	t.Run("Parses lock info", func(t *testing.T) {
+		got, locked := parseLockInfo(output)
+		want := LockInfo{
+			ID:        "5f2b1c3e-8f3a-4b7e-9d2a-1c0b6e7f8a9d",
+			Path:      "my-bucket/terraform.tfstate",
+			Operation: "OperationTypeApply",
+			Who:       "alice@laptop",
+			Version:   "1.5.7",
+			Created:   "2024-03-01 10:15:00.123456 +0000 UTC",
+		}
+
+		if !locked {
+			t.Fatal("Expected lock to be detected")
+		}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Plan reports lock", func(t *testing.T) {
+		got := parsePlanOutput(output)
+		want := TerraformChanges{LockError.Value(), LockError.Value(), LockError.Value()}
+
+		assertMatchingChanges(t, got, want)
+	})
+
+	t.Run("No lock", func(t *testing.T) {
+		if _, locked := parseLockInfo("Error: Unsupported attribute"); locked {
+			t.Error("Expected no lock to be detected")
+		}
+	})
+}
+
 func TestRegexMatch(t *testing.T) {
 	t.Run("Parses change values", func(t *testing.T) {
 		output := "Plan: 8 to add, 7 to change, 8 to destroy."
@@ -76,3 +135,14 @@ func assertMatchingChanges(t *testing.T, got, want TerraformChanges) {
 		t.Errorf("Expected TerraformChanges{%d,%d,%d}, got %v", want.Add, want.Change, want.Destroy, got)
 	}
 }
+
+func TestApplyClearsLock(t *testing.T) {
+	project := Project{
+		Path: fakeTerraform(t, "echo 'Apply complete! Resources: 1 added, 0 changed, 0 destroyed.'"),
+		Lock: LockInfo{ID: "9db590f1-b6fe-c5f2-2678-8804f089deba"},
+	}
+	runApply(&project)()
+	if project.Lock.ID != "" {
+		t.Errorf("Expected the lock to be cleared by a successful apply, got %v", project.Lock)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const AutoTheme = "auto"
+
+// Theme holds the colors used by every style, plus the symbols used to show
+// project status. Symbols never rely on color alone, so they stay readable
+// with NO_COLOR or the monochrome theme.
+type Theme struct {
+	Text          string  `json:"text"`
+	Muted         string  `json:"muted"`
+	Border        string  `json:"border"`
+	Highlight     string  `json:"highlight"`
+	HighlightText string  `json:"highlightText"`
+	Header        string  `json:"header"`
+	HeaderPrimary string  `json:"headerPrimary"`
+	FilterTyping  string  `json:"filterTyping"`
+	FilterSet     string  `json:"filterSet"`
+	Success       string  `json:"success"`
+	Error         string  `json:"error"`
+	Warning       string  `json:"warning"`
+	Title         string  `json:"title"`
+	ProgressStart string  `json:"progressStart"`
+	ProgressEnd   string  `json:"progressEnd"`
+	Symbols       Symbols `json:"symbols"`
+}
+
+type Symbols struct {
+	Valid       string `json:"valid"`
+	Invalid     string `json:"invalid"`
+	Unknown     string `json:"unknown"`
+	Timeout     string `json:"timeout"`
+	Selected    string `json:"selected"`
+	PlanError   string `json:"planError"`
+	PlanDrift   string `json:"planDrift"`
+	PlanLocked  string `json:"planLocked"`
+	PlanTimeout string `json:"planTimeout"`
+}
+
+var defaultSymbols = Symbols{
+	Valid:       "✓",
+	Invalid:     "✗",
+	Unknown:     "?",
+	Timeout:     "⧗",
+	Selected:    "•",
+	PlanError:   "✗ Error",
+	PlanDrift:   "≈ Drift",
+	PlanLocked:  "⊘ Locked",
+	PlanTimeout: "⧗ Timeout",
+}
+
+var (
+	darkTheme = Theme{
+		Text:          "#DCD7BA",
+		Border:        "#737c73",
+		Highlight:     "#7a8382",
+		HighlightText: "#181616",
+		Header:        "#8ba4b0",
+		HeaderPrimary: "#8992a7",
+		FilterTyping:  "#b6927b",
+		FilterSet:     "#87a987",
+		Success:       "#87a987",
+		Error:         "#c4746e",
+		Warning:       "#b6927b",
+		Title:         "#8ba4b0",
+		ProgressStart: "#737c73",
+		ProgressEnd:   "#8992a7",
+		Symbols:       defaultSymbols,
+	}
+	lightTheme = Theme{
+		Text:          "#545464",
+		Border:        "#8a8980",
+		Highlight:     "#c7d7e0",
+		HighlightText: "#1f1f28",
+		Header:        "#4d699b",
+		HeaderPrimary: "#624c83",
+		FilterTyping:  "#cc6d00",
+		FilterSet:     "#6f894e",
+		Success:       "#6f894e",
+		Error:         "#c84053",
+		Warning:       "#cc6d00",
+		Title:         "#4d699b",
+		ProgressStart: "#8a8980",
+		ProgressEnd:   "#624c83",
+		Symbols:       defaultSymbols,
+	}
+	highContrastTheme = Theme{
+		Text:          "#ffffff",
+		Border:        "#ffffff",
+		Highlight:     "#ffff00",
+		HighlightText: "#000000",
+		Header:        "#00ffff",
+		HeaderPrimary: "#ffffff",
+		FilterTyping:  "#ffff00",
+		FilterSet:     "#00ff00",
+		Success:       "#00ff00",
+		Error:         "#ff5f5f",
+		Warning:       "#ffff00",
+		Title:         "#00ffff",
+		ProgressStart: "#00ffff",
+		ProgressEnd:   "#00ffff",
+		Symbols:       defaultSymbols,
+	}
+	monochromeTheme = Theme{
+		Symbols: defaultSymbols,
+	}
+)
+
+var builtinThemes = map[string]Theme{
+	"dark":          darkTheme,
+	"light":         lightTheme,
+	"high-contrast": highContrastTheme,
+	"monochrome":    monochromeTheme,
+}
+
+// resolveTheme picks the theme to use. NO_COLOR selects the monochrome theme
+// whatever is configured; otherwise the automatic theme follows the terminal
+// background, and custom themes from the config file start from the built-in
+// theme named in "base" (dark by default) and override it.
+func resolveTheme(name string, custom map[string]json.RawMessage) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return monochromeTheme, nil
+	}
+
+	if name == "" || name == AutoTheme {
+		switch {
+		case lipgloss.HasDarkBackground():
+			return darkTheme, nil
+		default:
+			return lightTheme, nil
+		}
+	}
+
+	if raw, ok := custom[name]; ok {
+		var base struct {
+			Base string `json:"base"`
+		}
+		if err := json.Unmarshal(raw, &base); err != nil {
+			return Theme{}, fmt.Errorf("theme %q: %w", name, err)
+		}
+
+		theme := darkTheme
+		if base.Base != "" {
+			if theme, ok = builtinThemes[base.Base]; !ok {
+				return Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, base.Base)
+			}
+		}
+		if err := json.Unmarshal(raw, &theme); err != nil {
+			return Theme{}, fmt.Errorf("theme %q: %w", name, err)
+		}
+		return theme, nil
+	}
+
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q", name)
+}
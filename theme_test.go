@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveTheme(t *testing.T) {
+	custom := map[string]json.RawMessage{
+		"mine":   json.RawMessage(`{"base": "light", "error": "#ff0000", "symbols": {"valid": "ok"}}`),
+		"broken": json.RawMessage(`{"base": "sepia"}`),
+	}
+
+	t.Run("Built-in theme", func(t *testing.T) {
+		theme, err := resolveTheme("high-contrast", custom)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if theme != highContrastTheme {
+			t.Error("Expected the high-contrast theme")
+		}
+	})
+
+	t.Run("Custom theme extends base", func(t *testing.T) {
+		theme, err := resolveTheme("mine", custom)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if theme.Error != "#ff0000" || theme.Text != lightTheme.Text {
+			t.Errorf("Expected light theme with a custom error color, got %+v", theme)
+		}
+		if theme.Symbols.Valid != "ok" || theme.Symbols.Invalid != defaultSymbols.Invalid {
+			t.Errorf("Expected only the valid symbol to change, got %+v", theme.Symbols)
+		}
+	})
+
+	t.Run("NO_COLOR selects monochrome", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		theme, err := resolveTheme(AutoTheme, custom)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if theme != monochromeTheme {
+			t.Error("Expected the monochrome theme")
+		}
+
+		theme, err = resolveTheme("dark", custom)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if theme != monochromeTheme {
+			t.Error("Expected NO_COLOR to override a configured theme")
+		}
+	})
+
+	t.Run("Unknown themes", func(t *testing.T) {
+		if _, err := resolveTheme("solarized", custom); err == nil {
+			t.Error("Expected an error for an unknown theme")
+		}
+		if _, err := resolveTheme("broken", custom); err == nil {
+			t.Error("Expected an error for an unknown base theme")
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TreeNode is a directory below the search path. It is a group if projects
+// are nested beneath it, a project if it holds one, or both.
+type TreeNode struct {
+	ID       string
+	Name     string
+	Project  *Project
+	Children []*TreeNode
+	Depth    int
+}
+
+type TreeModel struct {
+	collapsed map[string]bool
+	cursor    string
+	offset    int
+}
+
+func createTreeModel() TreeModel {
+	return TreeModel{collapsed: map[string]bool{}}
+}
+
+// buildProjectTree groups projects by their directories relative to root.
+func buildProjectTree(projects []Project, root string) []*TreeNode {
+	top := &TreeNode{Depth: -1}
+
+	for i := range projects {
+		rel, err := filepath.Rel(root, projects[i].Path)
+		if err != nil {
+			rel = projects[i].Path
+		}
+
+		node := top
+		for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+			node = node.child(part)
+		}
+		node.Project = &projects[i]
+	}
+
+	return top.Children
+}
+
+func (n *TreeNode) child(name string) *TreeNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+
+	id := name
+	if n.ID != "" {
+		id = n.ID + "/" + name
+	}
+	child := &TreeNode{ID: id, Name: name, Depth: n.Depth + 1}
+	n.Children = append(n.Children, child)
+	slices.SortFunc(n.Children, func(a, b *TreeNode) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return child
+}
+
+func (n *TreeNode) isGroup() bool {
+	return len(n.Children) > 0
+}
+
+// projects returns every project at or beneath the node.
+func (n *TreeNode) projects() []Project {
+	projects := []Project{}
+	if n.Project != nil {
+		projects = append(projects, *n.Project)
+	}
+	for _, child := range n.Children {
+		projects = append(projects, child.projects()...)
+	}
+	return projects
+}
+
+// aggregateChanges sums the planned changes beneath a node. Projects whose
+// plan failed are counted separately instead of being added up.
+func aggregateChanges(projects []Project) (TerraformChanges, int) {
+	total := TerraformChanges{}
+	failed := 0
+	for _, project := range projects {
+		if project.PlanChanges.Add < 0 {
+			failed++
+			continue
+		}
+		total.Add += project.PlanChanges.Add
+		total.Change += project.PlanChanges.Change
+		total.Destroy += project.PlanChanges.Destroy
+	}
+	return total, failed
+}
+
+func flattenProjectTree(nodes []*TreeNode, collapsed map[string]bool) []*TreeNode {
+	visible := []*TreeNode{}
+	for _, node := range nodes {
+		visible = append(visible, node)
+		if !collapsed[node.ID] {
+			visible = append(visible, flattenProjectTree(node.Children, collapsed)...)
+		}
+	}
+	return visible
+}
+
+func (m *TreeModel) visibleNodes(projects []Project) []*TreeNode {
+	return flattenProjectTree(buildProjectTree(projects, SearchPath), m.collapsed)
+}
+
+func (m *TreeModel) cursorIndex(visible []*TreeNode) int {
+	for i, node := range visible {
+		if node.ID == m.cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *TreeModel) highlighted(projects []Project) *TreeNode {
+	visible := m.visibleNodes(projects)
+	if len(visible) == 0 {
+		return nil
+	}
+	return visible[m.cursorIndex(visible)]
+}
+
+func (m *TreeModel) moveCursor(projects []Project, delta int, height int) {
+	visible := m.visibleNodes(projects)
+	if len(visible) == 0 {
+		return
+	}
+
+	i := max(0, min(m.cursorIndex(visible)+delta, len(visible)-1))
+	m.cursor = visible[i].ID
+
+	if i < m.offset {
+		m.offset = i
+	} else if i >= m.offset+height {
+		m.offset = i - height + 1
+	}
+}
+
+// clickRow moves the cursor to a row of the rendered tree, counted from the
+// first row shown. It reports whether there is a node on that row.
+func (m *TreeModel) clickRow(projects []Project, row int, height int) bool {
+	visible := m.visibleNodes(projects)
+	i := min(m.offset, max(len(visible)-height, 0)) + row
+	if row < 0 || row >= height || i >= len(visible) {
+		return false
+	}
+	m.cursor = visible[i].ID
+	return true
+}
+
+// setCollapsed expands or collapses the highlighted group. Collapsing a
+// project or an already collapsed group moves the cursor to its parent.
+func (m *TreeModel) setCollapsed(projects []Project, collapsed bool) {
+	node := m.highlighted(projects)
+	if node == nil {
+		return
+	}
+
+	if node.isGroup() && m.collapsed[node.ID] != collapsed {
+		m.collapsed[node.ID] = collapsed
+	} else if i := strings.LastIndex(node.ID, "/"); collapsed && i >= 0 {
+		m.cursor = node.ID[:i]
+	}
+}
+
+// toggleSelection selects every project beneath the highlighted node, or
+// deselects them if they are all selected already.
+func (m *TreeModel) toggleSelection(projects []Project, selected []string) []string {
+	node := m.highlighted(projects)
+	if node == nil {
+		return selected
+	}
+
+	paths := []string{}
+	allSelected := true
+	for _, project := range node.projects() {
+		paths = append(paths, project.Path)
+		allSelected = allSelected && slices.Contains(selected, project.Path)
+	}
+
+	if allSelected {
+		return slices.DeleteFunc(selected, func(path string) bool {
+			return slices.Contains(paths, path)
+		})
+	}
+	for _, path := range paths {
+		if !slices.Contains(selected, path) {
+			selected = append(selected, path)
+		}
+	}
+	return selected
+}
+
+func (m *TreeModel) renderTree(projects []Project, selected []string, filter string, width int, height int) string {
+	visible := m.visibleNodes(projects)
+	cursor := m.cursorIndex(visible)
+	offset := min(m.offset, max(len(visible)-height, 0))
+
+	nameWidth := 0
+	for _, node := range visible {
+		nameWidth = max(nameWidth, node.Depth*2+2+lipgloss.Width(node.Name))
+	}
+
+	lines := []string{}
+	end := min(offset+height, len(visible))
+	for i := offset; i < end; i++ {
+		node := visible[i]
+
+		marker := "  "
+		if node.isGroup() {
+			marker = "▾ "
+			if m.collapsed[node.ID] {
+				marker = "▸ "
+			}
+		}
+
+		nodeProjects := node.projects()
+		selectedCount := 0
+		for _, project := range nodeProjects {
+			if slices.Contains(selected, project.Path) {
+				selectedCount++
+			}
+		}
+		selection := "   "
+		if selectedCount == len(nodeProjects) {
+			selection = fmt.Sprintf(" %s ", currentTheme.Symbols.Selected)
+		} else if selectedCount > 0 {
+			selection = " - "
+		}
+
+		name := strings.Repeat("  ", node.Depth) + marker + node.Name
+		details := ""
+		if node.Project != nil && !node.isGroup() {
+			add, change, destroy := formatChanges(node.Project.PlanChanges)
+			details = fmt.Sprintf("%s  +%s ~%s -%s", formatValid(node.Project.Valid), add, change, destroy)
+			if node.Project.PlanChanges.Add < 0 {
+				details = fmt.Sprintf("%s  %s", formatValid(node.Project.Valid), add)
+			}
+		} else {
+			total, failed := aggregateChanges(nodeProjects)
+			count := fmt.Sprintf("  %d projects", len(nodeProjects))
+			if len(nodeProjects) == 1 {
+				count = "  1 project"
+			}
+			details = fmt.Sprintf("   +%d ~%d -%d", total.Add, total.Change, total.Destroy)
+			details += tableDate.Render(count)
+			if failed > 0 {
+				details += errorStyle.Render(fmt.Sprintf("  %s %d", currentTheme.Symbols.Invalid, failed))
+			}
+		}
+
+		line := selection + name + strings.Repeat(" ", nameWidth-lipgloss.Width(name)+2) + details
+		line = lipgloss.NewStyle().Width(width - 2).MaxWidth(width - 2).Render(line)
+		if i == cursor {
+			line = tableHighlighted.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(visible) == 0 {
+		lines = append(lines, tableDate.Render(" No projects"))
+	}
+
+	footer := tableDate.Render(fmt.Sprintf(" # Projects: %d  |  Tree view", len(projects)))
+	box := tableBase.Copy().Border(lipgloss.RoundedBorder()).Render(strings.Join(lines, "\n"))
+	return "\n\n" + filter + "\n" + box + "\n" + footer + "\n\n"
+}
+
+// treeChromeHeight is the number of lines around the rows of the tree: the
+// spacing, filter, footer and borders.
+const treeChromeHeight = 8
+
+func treeHeight(height int) int {
+	return max(height-treeChromeHeight, 1)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func treeProjects() []Project {
+	return []Project{
+		{Name: "Project1", Path: "/services/Project1", PlanChanges: TerraformChanges{1, 2, 3}},
+		{Name: "Project2", Path: "/services/Project2", PlanChanges: TerraformChanges{4, 0, 0}},
+		{Name: "Project3", Path: "/services/OtherService/Project3", PlanChanges: TerraformChanges{PlanError.Value(), PlanError.Value(), PlanError.Value()}},
+	}
+}
+
+func nodeIDs(nodes []*TreeNode) []string {
+	ids := []string{}
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+func TestBuildProjectTree(t *testing.T) {
+	tree := buildProjectTree(treeProjects(), "/services")
+
+	t.Run("Groups by directory", func(t *testing.T) {
+		expected := []string{"OtherService", "OtherService/Project3", "Project1", "Project2"}
+		if ids := nodeIDs(flattenProjectTree(tree, map[string]bool{})); !slices.Equal(ids, expected) {
+			t.Errorf("Expected %v, got %v", expected, ids)
+		}
+	})
+
+	t.Run("Collapsed groups hide children", func(t *testing.T) {
+		expected := []string{"OtherService", "Project1", "Project2"}
+		ids := nodeIDs(flattenProjectTree(tree, map[string]bool{"OtherService": true}))
+		if !slices.Equal(ids, expected) {
+			t.Errorf("Expected %v, got %v", expected, ids)
+		}
+	})
+
+	t.Run("Project at the root", func(t *testing.T) {
+		tree := buildProjectTree([]Project{{Name: "services", Path: "/services"}}, "/services")
+		if len(tree) != 1 || tree[0].ID != "." || tree[0].Project == nil {
+			t.Errorf("Expected a single project node, got %v", nodeIDs(tree))
+		}
+	})
+}
+
+func TestAggregateChanges(t *testing.T) {
+	total, failed := aggregateChanges(treeProjects())
+	if total != (TerraformChanges{5, 2, 3}) {
+		t.Errorf("Expected 5/2/3, got %d/%d/%d", total.Add, total.Change, total.Destroy)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed project, got %d", failed)
+	}
+}
+
+func TestTreeSelection(t *testing.T) {
+	SearchPath = "/services"
+	projects := treeProjects()
+	tree := createTreeModel()
+	tree.cursor = "OtherService"
+
+	selected := tree.toggleSelection(projects, []string{"/services/Project1"})
+	expected := []string{"/services/Project1", "/services/OtherService/Project3"}
+	if !slices.Equal(selected, expected) {
+		t.Errorf("Expected %v, got %v", expected, selected)
+	}
+
+	selected = tree.toggleSelection(projects, selected)
+	if !slices.Equal(selected, []string{"/services/Project1"}) {
+		t.Errorf("Expected the group to be deselected, got %v", selected)
+	}
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	TerraformVersionFile = ".terraform-version"
+	ToolVersionsFile     = ".tool-versions"
+	TerraformBinary      = "terraform"
+
+	// pinLatest and pinMinRequired are the tfenv keywords for the newest
+	// installed version and the oldest one that satisfies required_version.
+	pinLatest      = "latest"
+	pinMinRequired = "min-required"
+)
+
+var (
+	versionPattern         = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?$`)
+	requiredVersionPattern = regexp.MustCompile(`(?m)^\s*required_version\s*=\s*"([^"]*)"`)
+	constraintOperators    = []string{">=", "<=", "!=", "~>", "=", ">", "<"}
+)
+
+// Version is a Terraform version such as 1.5.7 or 1.6.0-beta1. Missing minor
+// and patch numbers are zero, but precision remembers how many were given for
+// the ~> operator.
+type Version struct {
+	Segments   [3]int
+	Prerelease string
+	precision  int
+}
+
+func parseVersion(text string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version %q", text)
+	}
+
+	version := Version{Prerelease: match[4]}
+	for i, segment := range match[1:4] {
+		if segment == "" {
+			break
+		}
+		version.Segments[i], _ = strconv.Atoi(segment)
+		version.precision = i + 1
+	}
+	return version, nil
+}
+
+func (v Version) String() string {
+	text := fmt.Sprintf("%d.%d.%d", v.Segments[0], v.Segments[1], v.Segments[2])
+	if v.Prerelease != "" {
+		text += "-" + v.Prerelease
+	}
+	return text
+}
+
+// compare returns -1, 0 or 1 if v is older than, the same as or newer than
+// other. Prereleases are older than the release they lead up to.
+func (v Version) compare(other Version) int {
+	for i := range v.Segments {
+		if v.Segments[i] != other.Segments[i] {
+			if v.Segments[i] < other.Segments[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	return strings.Compare(v.Prerelease, other.Prerelease)
+}
+
+// VersionConstraint is a single condition of a required_version, such as
+// ">= 1.5".
+type VersionConstraint struct {
+	Op      string
+	Version Version
+}
+
+// VersionConstraints are the comma separated conditions of a
+// required_version. A version must satisfy all of them.
+type VersionConstraints []VersionConstraint
+
+func parseConstraints(text string) (VersionConstraints, error) {
+	constraints := VersionConstraints{}
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := "="
+		for _, operator := range constraintOperators {
+			if strings.HasPrefix(part, operator) {
+				op = operator
+				part = part[len(operator):]
+				break
+			}
+		}
+		version, err := parseVersion(part)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, VersionConstraint{op, version})
+	}
+	return constraints, nil
+}
+
+func (c VersionConstraint) allows(v Version) bool {
+	order := v.compare(c.Version)
+	switch c.Op {
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case "~>":
+		// only the last given segment may increase: ~> 1.2 allows 1.9 but not
+		// 2.0, ~> 1.2.3 allows 1.2.9 but not 1.3.0
+		for i := 0; i < c.Version.precision-1; i++ {
+			if v.Segments[i] != c.Version.Segments[i] {
+				return false
+			}
+		}
+		return order >= 0
+	default:
+		return order == 0
+	}
+}
+
+func (c VersionConstraints) allows(v Version) bool {
+	for _, constraint := range c {
+		if !constraint.allows(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// VersionMismatchError is returned instead of running Terraform in a project
+// that no available Terraform binary can run.
+type VersionMismatchError struct {
+	Required string
+	Resolved string
+}
+
+func (e VersionMismatchError) Error() string {
+	if e.Resolved == "" {
+		return fmt.Sprintf("no installed Terraform version satisfies %s", e.Required)
+	}
+	return fmt.Sprintf("Terraform %s does not satisfy the required version %s", e.Resolved, e.Required)
+}
+
+// loadRequiredVersion reads the version requirements of a project: the
+// required_version of its .tf files and the version pinned in
+// .terraform-version (tfenv) or .tool-versions (asdf).
+func loadRequiredVersion(filesystem fs.FS, dir string, project *Project) {
+	required := []string{}
+	files, _ := fs.Glob(filesystem, path.Join(dir, "*.tf"))
+	for _, file := range files {
+		data, err := fs.ReadFile(filesystem, file)
+		if err != nil {
+			continue
+		}
+		for _, match := range requiredVersionPattern.FindAllStringSubmatch(string(data), -1) {
+			required = append(required, match[1])
+		}
+	}
+	project.RequiredVersion = strings.Join(required, ", ")
+
+	if data, err := fs.ReadFile(filesystem, path.Join(dir, TerraformVersionFile)); err == nil {
+		project.PinnedVersion = strings.TrimSpace(string(data))
+		return
+	}
+	if data, err := fs.ReadFile(filesystem, path.Join(dir, ToolVersionsFile)); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == TerraformBinary {
+				project.PinnedVersion = fields[1]
+				return
+			}
+		}
+	}
+}
+
+// InstalledVersion is a Terraform binary in the configured versions
+// directory.
+type InstalledVersion struct {
+	Version Version
+	Path    string
+}
+
+// findInstalledVersions lists the Terraform binaries in dir, newest first.
+// Both the tfenv layout (<dir>/<version>/terraform, or a tfenv root with a
+// versions directory) and the asdf layout (<dir>/<version>/bin/terraform)
+// are understood.
+func findInstalledVersions(dir string) []InstalledVersion {
+	if dir == "" {
+		return nil
+	}
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "versions")); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, "versions")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	installed := []InstalledVersion{}
+	for _, entry := range entries {
+		version, err := parseVersion(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		for _, binary := range []string{
+			filepath.Join(dir, entry.Name(), TerraformBinary),
+			filepath.Join(dir, entry.Name(), "bin", TerraformBinary),
+		} {
+			if info, err := os.Stat(binary); err == nil && !info.IsDir() {
+				installed = append(installed, InstalledVersion{version, binary})
+				break
+			}
+		}
+	}
+	slices.SortFunc(installed, func(a, b InstalledVersion) int {
+		return b.Version.compare(a.Version)
+	})
+	return installed
+}
+
+// pathVersion returns the version of the terraform binary on the PATH, or an
+// empty string if there is none.
+var pathVersion = sync.OnceValue(func() string {
+	out, err := exec.Command(TerraformBinary, "version", "-json").Output()
+	if err != nil {
+		return ""
+	}
+	var version struct {
+		TerraformVersion string `json:"terraform_version"`
+	}
+	if json.Unmarshal(out, &version) != nil {
+		return ""
+	}
+	return version.TerraformVersion
+})
+
+// resolveTerraform picks the binary a project runs with. A pinned version is
+// used exactly, otherwise the newest installed version that satisfies
+// required_version is used. Projects without requirements, or that no
+// installed version satisfies, use terraform from the PATH. A
+// VersionMismatchError is returned if the chosen binary does not satisfy the
+// requirements.
+func resolveTerraform(project Project, installed []InstalledVersion, pathVersion func() string) (string, string, error) {
+	constraints, err := parseConstraints(project.RequiredVersion)
+	if err != nil {
+		// Terraform reports invalid constraints itself
+		return TerraformBinary, "", nil
+	}
+
+	candidates := []InstalledVersion{}
+	for _, version := range installed {
+		if constraints.allows(version.Version) {
+			candidates = append(candidates, version)
+		}
+	}
+
+	pin := project.PinnedVersion
+	switch {
+	case pin == pinLatest && len(candidates) > 0:
+		return candidates[0].Path, candidates[0].Version.String(), nil
+
+	case pin == pinMinRequired && len(candidates) > 0:
+		last := candidates[len(candidates)-1]
+		return last.Path, last.Version.String(), nil
+
+	case pin != "" && pin != pinLatest && pin != pinMinRequired:
+		pinned, err := parseVersion(pin)
+		if err != nil {
+			return TerraformBinary, "", nil
+		}
+		if !constraints.allows(pinned) {
+			return TerraformBinary, pinned.String(), VersionMismatchError{project.RequiredVersion, pinned.String()}
+		}
+		for _, version := range installed {
+			if version.Version.compare(pinned) == 0 {
+				return version.Path, version.Version.String(), nil
+			}
+		}
+		if current, err := parseVersion(pathVersion()); err == nil && current.compare(pinned) == 0 {
+			return TerraformBinary, current.String(), nil
+		}
+		return TerraformBinary, "", VersionMismatchError{pinned.String(), ""}
+
+	case len(constraints) > 0 && len(candidates) > 0:
+		return candidates[0].Path, candidates[0].Version.String(), nil
+	}
+
+	current, err := parseVersion(pathVersion())
+	if err != nil {
+		// the version can't be checked, so leave it to Terraform
+		return TerraformBinary, "", nil
+	}
+	if !constraints.allows(current) {
+		return TerraformBinary, current.String(), VersionMismatchError{project.RequiredVersion, current.String()}
+	}
+	return TerraformBinary, current.String(), nil
+}
+
+// binaryCache remembers the Terraform binary resolved for each project
+// directory, so that every command run in a project uses the same one.
+type binaryCache struct {
+	mu       sync.Mutex
+	binaries map[string]resolvedBinary
+}
+
+type resolvedBinary struct {
+	path string
+	err  error
+}
+
+var terraformBinaries = &binaryCache{binaries: map[string]resolvedBinary{}}
+
+func (c *binaryCache) set(dir string, path string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.binaries[dir] = resolvedBinary{path, err}
+}
+
+// get returns the binary for a project directory. Directories that were
+// never resolved use terraform from the PATH.
+func (c *binaryCache) get(dir string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if binary, ok := c.binaries[dir]; ok {
+		return binary.path, binary.err
+	}
+	return TerraformBinary, nil
+}
+
+// resolveVersions resolves the Terraform binary of every project using the
+// versions installed in the configured directory.
+func resolveVersions(projects []Project) {
+	installed := findInstalledVersions(Settings.TerraformVersions)
+	for i := range projects {
+		project := &projects[i]
+		binary, version, err := resolveTerraform(*project, installed, pathVersion)
+		project.ResolvedVersion = version
+		project.VersionMismatch = err
+		terraformBinaries.set(project.Path, binary, err)
+	}
+}
+
+// formatRequiredVersion returns the pinned version of a project, or its
+// required_version if nothing is pinned.
+func formatRequiredVersion(project Project) string {
+	if project.PinnedVersion != "" {
+		return project.PinnedVersion
+	}
+	return project.RequiredVersion
+}
+
+// formatResolvedVersion renders the version a project runs with, marked as
+// an error if it does not satisfy the requirements.
+func formatResolvedVersion(project Project) string {
+	version := project.ResolvedVersion
+	if version == "" {
+		version = "-"
+	}
+	if project.VersionMismatch != nil {
+		return errorStyle.Render(version + " " + currentTheme.Symbols.Invalid)
+	}
+	return version
+}
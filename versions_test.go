@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestVersionConstraints(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">= 1.5", "1.5.0", true},
+		{">= 1.5", "1.4.9", false},
+		{">= 1.5, < 2.0", "2.0.0", false},
+		{"~> 1.5", "1.9.3", true},
+		{"~> 1.5", "2.0.0", false},
+		{"~> 1.5.2", "1.5.9", true},
+		{"~> 1.5.2", "1.6.0", false},
+		{"1.6.0", "1.6.0", true},
+		{"!= 1.6.0", "1.6.0", false},
+		{">= 1.6.0", "1.6.0-beta1", false},
+		{"", "0.12.0", true},
+	}
+
+	for _, c := range cases {
+		constraints, err := parseConstraints(c.constraint)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.constraint, err)
+		}
+		version, _ := parseVersion(c.version)
+		if got := constraints.allows(version); got != c.want {
+			t.Errorf("Expected %q to allow %s: %t, got %t", c.constraint, c.version, c.want, got)
+		}
+	}
+
+	if _, err := parseConstraints(">= one"); err == nil {
+		t.Error("Expected an error for an invalid version")
+	}
+}
+
+func TestLoadRequiredVersion(t *testing.T) {
+	t.Run("reads required_version and .terraform-version", func(t *testing.T) {
+		filesystem := fstest.MapFS{
+			"project/versions.tf":        {Data: []byte("terraform {\n  required_version = \">= 1.5, < 2.0\"\n}\n")},
+			"project/.terraform-version": {Data: []byte("1.5.7\n")},
+		}
+
+		var project Project
+		loadRequiredVersion(filesystem, "project", &project)
+		if project.RequiredVersion != ">= 1.5, < 2.0" || project.PinnedVersion != "1.5.7" {
+			t.Errorf("Unexpected versions: %q, %q", project.RequiredVersion, project.PinnedVersion)
+		}
+	})
+
+	t.Run("reads .tool-versions", func(t *testing.T) {
+		filesystem := fstest.MapFS{
+			"project/.tool-versions": {Data: []byte("nodejs 20.1.0\nterraform 1.6.2\n")},
+		}
+
+		var project Project
+		loadRequiredVersion(filesystem, "project", &project)
+		if project.RequiredVersion != "" || project.PinnedVersion != "1.6.2" {
+			t.Errorf("Unexpected versions: %q, %q", project.RequiredVersion, project.PinnedVersion)
+		}
+	})
+}
+
+func TestFindInstalledVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, binary := range []string{"1.5.7/terraform", "1.6.2/bin/terraform", "notes/terraform"} {
+		path := filepath.Join(dir, binary)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte{}, 0o755)
+	}
+	os.MkdirAll(filepath.Join(dir, "1.7.0"), 0o755)
+
+	installed := findInstalledVersions(dir)
+	if len(installed) != 2 {
+		t.Fatalf("Expected 2 installed versions, got %v", installed)
+	}
+	if installed[0].Version.String() != "1.6.2" || installed[0].Path != filepath.Join(dir, "1.6.2/bin/terraform") {
+		t.Errorf("Expected the newest version first, got %v", installed[0])
+	}
+}
+
+func TestResolveTerraform(t *testing.T) {
+	installed := []InstalledVersion{
+		{Version{Segments: [3]int{1, 6, 2}}, "/versions/1.6.2/terraform"},
+		{Version{Segments: [3]int{1, 5, 7}}, "/versions/1.5.7/terraform"},
+	}
+	onPath := func() string { return "1.4.0" }
+
+	cases := []struct {
+		name     string
+		project  Project
+		binary   string
+		version  string
+		mismatch bool
+	}{
+		{"No requirements", Project{}, TerraformBinary, "1.4.0", false},
+		{"Newest satisfying", Project{RequiredVersion: ">= 1.5"}, "/versions/1.6.2/terraform", "1.6.2", false},
+		{"Pinned", Project{RequiredVersion: ">= 1.5", PinnedVersion: "1.5.7"}, "/versions/1.5.7/terraform", "1.5.7", false},
+		{"Min required", Project{RequiredVersion: ">= 1.5", PinnedVersion: "min-required"}, "/versions/1.5.7/terraform", "1.5.7", false},
+		{"Pinned on PATH", Project{PinnedVersion: "1.4.0"}, TerraformBinary, "1.4.0", false},
+		{"Pinned but not installed", Project{PinnedVersion: "1.3.0"}, TerraformBinary, "", true},
+		{"Pin outside constraint", Project{RequiredVersion: ">= 1.6", PinnedVersion: "1.5.7"}, TerraformBinary, "1.5.7", true},
+		{"Nothing satisfies", Project{RequiredVersion: ">= 2.0"}, TerraformBinary, "1.4.0", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			binary, version, err := resolveTerraform(c.project, installed, onPath)
+			if binary != c.binary || version != c.version {
+				t.Errorf("Expected %s %s, got %s %s", c.binary, c.version, binary, version)
+			}
+			if _, mismatch := err.(VersionMismatchError); mismatch != c.mismatch {
+				t.Errorf("Expected mismatch %t, got %v", c.mismatch, err)
+			}
+		})
+	}
+}
+
+func TestRunWithVersionMismatch(t *testing.T) {
+	dir := t.TempDir()
+	terraformBinaries.set(dir, TerraformBinary, VersionMismatchError{">= 2.0", "1.4.0"})
+
+	output, err := executeTerraformCommand(dir, Plan)
+	if _, mismatch := err.(VersionMismatchError); !mismatch {
+		t.Errorf("Expected a VersionMismatchError, got %v", err)
+	}
+	if output != "" {
+		t.Errorf("Expected Terraform not to run, got %q", output)
+	}
+}